@@ -0,0 +1,368 @@
+package parking
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+)
+
+// SlotAllocator picks the slot of the next vehicle among the free slots of a vehicle type.
+// It keeps its own index of the free slots, the lot tells it about every slot it takes or
+// frees and guards it with the vehicle type lock.
+type SlotAllocator interface {
+	// Allocate takes a free slot and returns its number, false when every slot is taken
+	Allocate() (int, bool)
+	// Take marks the slot taken, e.g. when the lot is recovered from its journal
+	Take(number int)
+	// Release marks the slot free
+	Release(number int)
+	// Free returns the number of free slots
+	Free() int
+}
+
+// AllocatorFactory creates the allocator of a vehicle type with slotCnt slots, all free
+type AllocatorFactory func(slotCnt int) SlotAllocator
+
+// SlotPosition : where a slot is, Distance from the entrance and the distance to each exit by name
+type SlotPosition struct {
+	Floor    int
+	Distance float64
+	Exits    map[string]float64
+}
+
+// getPosition returns the position of the slot, slots missing from the layout are on floor 0
+// and farther than every slot in it
+func getPosition(layout []SlotPosition, number int) SlotPosition {
+	if number < len(layout) {
+		return layout[number]
+	}
+	return SlotPosition{Distance: math.MaxFloat64}
+}
+
+// LowestNumberFirst allocates the free slot with the lowest number, the default allocator
+func LowestNumberFirst() AllocatorFactory {
+	return func(slotCnt int) SlotAllocator {
+		return newPriorityAllocator(slotCnt, func(number int) float64 {
+			return float64(number)
+		})
+	}
+}
+
+// NearestToEntrance allocates the free slot with the shortest distance from the entrance
+func NearestToEntrance(layout []SlotPosition) AllocatorFactory {
+	return func(slotCnt int) SlotAllocator {
+		return newPriorityAllocator(slotCnt, func(number int) float64 {
+			return getPosition(layout, number).Distance
+		})
+	}
+}
+
+// NearestToExit allocates the free slot closest to the exit, slots without a distance to it come last
+func NearestToExit(layout []SlotPosition, exit string) AllocatorFactory {
+	return func(slotCnt int) SlotAllocator {
+		return newPriorityAllocator(slotCnt, func(number int) float64 {
+			distance, ok := getPosition(layout, number).Exits[exit]
+			if !ok {
+				return math.MaxFloat64
+			}
+			return distance
+		})
+	}
+}
+
+// FloorsEvenly allocates on the floor with the lowest occupancy, nearest to the entrance
+// within the floor, ties go to the lower floor
+func FloorsEvenly(layout []SlotPosition) AllocatorFactory {
+	return func(slotCnt int) SlotAllocator {
+		return newFloorAllocator(slotCnt, layout)
+	}
+}
+
+// RandomSlot allocates a free slot at random, spreading the wear of the surface
+func RandomSlot(seed int64) AllocatorFactory {
+	return func(slotCnt int) SlotAllocator {
+		return newRandomAllocator(slotCnt, rand.New(rand.NewSource(seed)))
+	}
+}
+
+// slotHeap : free slots ordered by priority then number, index holds the heap position of
+// each slot, -1 for taken slots, so any slot is removed in O(log n). Heaps of disjoint slots,
+// such as the floors of a lot, share the index and priorities.
+type slotHeap struct {
+	numbers  []int
+	index    []int
+	priority []float64
+}
+
+// newSlotIndex returns the shared index of slotCnt slots, none of them in a heap
+func newSlotIndex(slotCnt int) ([]int, []float64) {
+	index := make([]int, slotCnt)
+	for i := range index {
+		index[i] = -1
+	}
+	return index, make([]float64, slotCnt)
+}
+
+func newSlotHeap(slotNumbers []int, index []int, priorities []float64, priority func(number int) float64) *slotHeap {
+	slotHeap := &slotHeap{index: index, priority: priorities}
+	for _, number := range slotNumbers {
+		slotHeap.priority[number] = priority(number)
+		slotHeap.index[number] = len(slotHeap.numbers)
+		slotHeap.numbers = append(slotHeap.numbers, number)
+	}
+	heap.Init(slotHeap)
+	return slotHeap
+}
+
+func (slotHeap *slotHeap) Len() int {
+	return len(slotHeap.numbers)
+}
+
+func (slotHeap *slotHeap) Less(i, j int) bool {
+	first, second := slotHeap.numbers[i], slotHeap.numbers[j]
+	if slotHeap.priority[first] != slotHeap.priority[second] {
+		return slotHeap.priority[first] < slotHeap.priority[second]
+	}
+	return first < second
+}
+
+func (slotHeap *slotHeap) Swap(i, j int) {
+	slotHeap.numbers[i], slotHeap.numbers[j] = slotHeap.numbers[j], slotHeap.numbers[i]
+	slotHeap.index[slotHeap.numbers[i]] = i
+	slotHeap.index[slotHeap.numbers[j]] = j
+}
+
+func (slotHeap *slotHeap) Push(x interface{}) {
+	number := x.(int)
+	slotHeap.index[number] = len(slotHeap.numbers)
+	slotHeap.numbers = append(slotHeap.numbers, number)
+}
+
+func (slotHeap *slotHeap) Pop() interface{} {
+	last := len(slotHeap.numbers) - 1
+	number := slotHeap.numbers[last]
+	slotHeap.numbers = slotHeap.numbers[:last]
+	slotHeap.index[number] = -1
+	return number
+}
+
+func (slotHeap *slotHeap) contains(number int) bool {
+	return number >= 0 && number < len(slotHeap.index) && slotHeap.index[number] >= 0
+}
+
+func (slotHeap *slotHeap) take(number int) bool {
+	if !slotHeap.contains(number) {
+		return false
+	}
+	heap.Remove(slotHeap, slotHeap.index[number])
+	return true
+}
+
+func (slotHeap *slotHeap) release(number int) bool {
+	if number < 0 || number >= len(slotHeap.index) || slotHeap.contains(number) {
+		return false
+	}
+	heap.Push(slotHeap, number)
+	return true
+}
+
+// priorityAllocator : allocates the free slot with the lowest priority value
+type priorityAllocator struct {
+	free *slotHeap
+}
+
+func newPriorityAllocator(slotCnt int, priority func(number int) float64) *priorityAllocator {
+	index, priorities := newSlotIndex(slotCnt)
+	return &priorityAllocator{free: newSlotHeap(allSlots(slotCnt), index, priorities, priority)}
+}
+
+func (priorityAllocator *priorityAllocator) Allocate() (int, bool) {
+	if priorityAllocator.free.Len() == 0 {
+		return 0, false
+	}
+	return heap.Pop(priorityAllocator.free).(int), true
+}
+
+func (priorityAllocator *priorityAllocator) Take(number int) {
+	priorityAllocator.free.take(number)
+}
+
+func (priorityAllocator *priorityAllocator) Release(number int) {
+	priorityAllocator.free.release(number)
+}
+
+func (priorityAllocator *priorityAllocator) Free() int {
+	return priorityAllocator.free.Len()
+}
+
+// floor : free slots of one floor, position is the index in the floor heap
+type floor struct {
+	number   int
+	capacity int
+	free     *slotHeap
+	position int
+}
+
+func (floor *floor) occupancy() float64 {
+	return float64(floor.capacity-floor.free.Len()) / float64(floor.capacity)
+}
+
+// floorHeap : floors with free slots first, then by occupancy and floor number
+type floorHeap []*floor
+
+func (floorHeap floorHeap) Len() int {
+	return len(floorHeap)
+}
+
+func (floorHeap floorHeap) Less(i, j int) bool {
+	first, second := floorHeap[i], floorHeap[j]
+	if (first.free.Len() == 0) != (second.free.Len() == 0) {
+		return second.free.Len() == 0
+	}
+	if first.occupancy() != second.occupancy() {
+		return first.occupancy() < second.occupancy()
+	}
+	return first.number < second.number
+}
+
+func (floorHeap floorHeap) Swap(i, j int) {
+	floorHeap[i], floorHeap[j] = floorHeap[j], floorHeap[i]
+	floorHeap[i].position = i
+	floorHeap[j].position = j
+}
+
+func (floorHeap *floorHeap) Push(x interface{}) {
+	floor := x.(*floor)
+	floor.position = len(*floorHeap)
+	*floorHeap = append(*floorHeap, floor)
+}
+
+func (floorHeap *floorHeap) Pop() interface{} {
+	old := *floorHeap
+	floor := old[len(old)-1]
+	*floorHeap = old[:len(old)-1]
+	return floor
+}
+
+// floorAllocator : a heap of floors by occupancy, each with a heap of its free slots by distance
+type floorAllocator struct {
+	floors  floorHeap
+	floorOf []*floor
+	free    int
+}
+
+func newFloorAllocator(slotCnt int, layout []SlotPosition) *floorAllocator {
+	byFloor := make(map[int][]int)
+	var floorNumbers []int
+	for number := 0; number < slotCnt; number++ {
+		floorNumber := getPosition(layout, number).Floor
+		if _, ok := byFloor[floorNumber]; !ok {
+			floorNumbers = append(floorNumbers, floorNumber)
+		}
+		byFloor[floorNumber] = append(byFloor[floorNumber], number)
+	}
+	floorAllocator := &floorAllocator{floorOf: make([]*floor, slotCnt), free: slotCnt}
+	distance := func(number int) float64 {
+		return getPosition(layout, number).Distance
+	}
+	index, priorities := newSlotIndex(slotCnt)
+	for _, floorNumber := range floorNumbers {
+		slots := byFloor[floorNumber]
+		floor := &floor{number: floorNumber, capacity: len(slots), free: newSlotHeap(slots, index, priorities, distance)}
+		for _, number := range slots {
+			floorAllocator.floorOf[number] = floor
+		}
+		heap.Push(&floorAllocator.floors, floor)
+	}
+	return floorAllocator
+}
+
+func (floorAllocator *floorAllocator) Allocate() (int, bool) {
+	if floorAllocator.free == 0 {
+		return 0, false
+	}
+	floor := floorAllocator.floors[0]
+	number := heap.Pop(floor.free).(int)
+	heap.Fix(&floorAllocator.floors, floor.position)
+	floorAllocator.free--
+	return number, true
+}
+
+func (floorAllocator *floorAllocator) Take(number int) {
+	if number < 0 || number >= len(floorAllocator.floorOf) {
+		return
+	}
+	floor := floorAllocator.floorOf[number]
+	if floor.free.take(number) {
+		heap.Fix(&floorAllocator.floors, floor.position)
+		floorAllocator.free--
+	}
+}
+
+func (floorAllocator *floorAllocator) Release(number int) {
+	if number < 0 || number >= len(floorAllocator.floorOf) {
+		return
+	}
+	floor := floorAllocator.floorOf[number]
+	if floor.free.release(number) {
+		heap.Fix(&floorAllocator.floors, floor.position)
+		floorAllocator.free++
+	}
+}
+
+func (floorAllocator *floorAllocator) Free() int {
+	return floorAllocator.free
+}
+
+// randomAllocator : free slots in a slice with the position of each slot, removal swaps with the last
+type randomAllocator struct {
+	free     []int
+	position []int
+	random   *rand.Rand
+}
+
+func newRandomAllocator(slotCnt int, random *rand.Rand) *randomAllocator {
+	return &randomAllocator{free: allSlots(slotCnt), position: allSlots(slotCnt), random: random}
+}
+
+func (randomAllocator *randomAllocator) Allocate() (int, bool) {
+	if len(randomAllocator.free) == 0 {
+		return 0, false
+	}
+	number := randomAllocator.free[randomAllocator.random.Intn(len(randomAllocator.free))]
+	randomAllocator.Take(number)
+	return number, true
+}
+
+func (randomAllocator *randomAllocator) Take(number int) {
+	if number < 0 || number >= len(randomAllocator.position) || randomAllocator.position[number] < 0 {
+		return
+	}
+	last := len(randomAllocator.free) - 1
+	moved := randomAllocator.free[last]
+	randomAllocator.free[randomAllocator.position[number]] = moved
+	randomAllocator.position[moved] = randomAllocator.position[number]
+	randomAllocator.free = randomAllocator.free[:last]
+	randomAllocator.position[number] = -1
+}
+
+func (randomAllocator *randomAllocator) Release(number int) {
+	if number < 0 || number >= len(randomAllocator.position) || randomAllocator.position[number] >= 0 {
+		return
+	}
+	randomAllocator.position[number] = len(randomAllocator.free)
+	randomAllocator.free = append(randomAllocator.free, number)
+}
+
+func (randomAllocator *randomAllocator) Free() int {
+	return len(randomAllocator.free)
+}
+
+// allSlots returns the slot numbers 0 to slotCnt-1
+func allSlots(slotCnt int) []int {
+	numbers := make([]int, slotCnt)
+	for i := range numbers {
+		numbers[i] = i
+	}
+	return numbers
+}
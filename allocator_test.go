@@ -0,0 +1,135 @@
+package parking
+
+import (
+	"github.com/hbkkanna/parking/slot"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func allocateAll(allocator SlotAllocator) []int {
+	var numbers []int
+	for {
+		number, ok := allocator.Allocate()
+		if !ok {
+			return numbers
+		}
+		numbers = append(numbers, number)
+	}
+}
+
+func TestNearestToEntrance(t *testing.T) {
+	layout := []SlotPosition{{Distance: 30}, {Distance: 10}, {Distance: 20}, {Distance: 10}}
+	allocator := NearestToEntrance(layout)(5)
+	// equal distances go to the lower number, slots missing from the layout come last
+	if numbers := allocateAll(allocator); !reflect.DeepEqual(numbers, []int{1, 3, 2, 0, 4}) {
+		t.Errorf("unexpected order %v", numbers)
+	}
+	allocator.Release(2)
+	allocator.Release(0)
+	allocator.Release(2)
+	if allocator.Free() != 2 {
+		t.Errorf("expected 2 free slots, got %d", allocator.Free())
+	}
+	if number, _ := allocator.Allocate(); number != 2 {
+		t.Errorf("expected the released slot 2, got %d", number)
+	}
+}
+
+func TestNearestToExit(t *testing.T) {
+	layout := []SlotPosition{
+		{Exits: map[string]float64{"north": 5, "south": 40}},
+		{Exits: map[string]float64{"north": 25, "south": 10}},
+		{Exits: map[string]float64{"north": 15}},
+	}
+	if numbers := allocateAll(NearestToExit(layout, "north")(3)); !reflect.DeepEqual(numbers, []int{0, 2, 1}) {
+		t.Errorf("unexpected north order %v", numbers)
+	}
+	if numbers := allocateAll(NearestToExit(layout, "south")(3)); !reflect.DeepEqual(numbers, []int{1, 0, 2}) {
+		t.Errorf("unexpected south order %v", numbers)
+	}
+}
+
+func TestFloorsEvenly(t *testing.T) {
+	layout := []SlotPosition{
+		{Floor: 0, Distance: 2}, {Floor: 0, Distance: 1}, {Floor: 0, Distance: 3}, {Floor: 0, Distance: 4},
+		{Floor: 1, Distance: 2}, {Floor: 1, Distance: 1},
+	}
+	allocator := FloorsEvenly(layout)(6)
+	// by occupancy, a floor of 2 slots is as full after one vehicle as a floor of 4 after two
+	var numbers []int
+	for i := 0; i < 4; i++ {
+		number, _ := allocator.Allocate()
+		numbers = append(numbers, number)
+	}
+	if !reflect.DeepEqual(numbers, []int{1, 5, 0, 2}) {
+		t.Errorf("unexpected order %v", numbers)
+	}
+	allocator.Release(5)
+	if number, _ := allocator.Allocate(); number != 5 {
+		t.Errorf("expected the emptier floor, got %d", number)
+	}
+	allocator.Take(4)
+	if numbers := allocateAll(allocator); !reflect.DeepEqual(numbers, []int{3}) || allocator.Free() != 0 {
+		t.Errorf("unexpected remaining slots %v", numbers)
+	}
+}
+
+func TestRandomSlot(t *testing.T) {
+	first := allocateAll(RandomSlot(7)(50))
+	if second := allocateAll(RandomSlot(7)(50)); !reflect.DeepEqual(first, second) {
+		t.Errorf("the same seed must allocate the same slots")
+	}
+	seen := make(map[int]bool)
+	for _, number := range first {
+		seen[number] = true
+	}
+	if len(first) != 50 || len(seen) != 50 {
+		t.Errorf("every slot must be allocated once, got %v", first)
+	}
+
+	allocator := RandomSlot(7)(3)
+	allocator.Take(0)
+	allocator.Take(2)
+	allocator.Take(2)
+	if number, ok := allocator.Allocate(); !ok || number != 1 || allocator.Free() != 0 {
+		t.Errorf("expected the only free slot 1, got %d", number)
+	}
+}
+
+func TestLotAllocator(t *testing.T) {
+	layout := []SlotPosition{{Distance: 30}, {Distance: 10}, {Distance: 20}}
+	config := NewParkingConfig(slot.SCOOTER, 3, getMallTariff()[slot.SCOOTER]).SetAllocator(NearestToEntrance(layout))
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("open store failed %v", err)
+	}
+	defer store.Close()
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot, err := OpenParkingLot([]*ParkingConfig{config}, store, WithClock(clock))
+	if err != nil {
+		t.Fatalf("open parking lot failed %v", err)
+	}
+	nearest, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	second, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	if nearest.GetNumber() != 1 || second.GetNumber() != 2 {
+		t.Fatalf("unexpected slots %d %d", nearest.GetNumber(), second.GetNumber())
+	}
+	plot.UnPark(nearest)
+
+	// the recovered lot takes the occupied slots out of its allocator
+	recovered, err := OpenParkingLot([]*ParkingConfig{config}, store, WithClock(clock))
+	if err != nil {
+		t.Fatalf("recover parking lot failed %v", err)
+	}
+	for _, expected := range []int{1, 0} {
+		ticket, err := recovered.Park(slot.NewRoadVehicle(slot.SCOOTER))
+		if err != nil || ticket.GetNumber() != expected {
+			t.Fatalf("expected slot %d, got %v %v", expected, ticket, err)
+		}
+	}
+	if _, err := recovered.Park(slot.NewRoadVehicle(slot.SCOOTER)); err == nil {
+		t.Errorf("expected ErrNoSpace")
+	}
+}
@@ -0,0 +1,135 @@
+// Package calendar keeps dated events and public holidays that tariffs consult, e.g.
+//
+//	{
+//	  "location": "Europe/London",
+//	  "events": [
+//	    {"name": "Cup Final", "start": "2021-05-15T12:00:00+01:00", "end": "2021-05-15T20:00:00+01:00"}
+//	  ],
+//	  "holidays": [
+//	    {"name": "Christmas Day", "date": "2021-12-25"}
+//	  ]
+//	}
+//
+// Event times are RFC 3339, a holiday lasts its whole date in the calendar location, UTC when omitted.
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+var ErrInvalidEvent = errors.New("invalid event")
+
+// Event : named window, the end is exclusive
+type Event struct {
+	Name  string
+	Start time.Time
+	End   time.Time
+}
+
+// Overlaps reports whether the event shares any time with [start, end)
+func (event Event) Overlaps(start time.Time, end time.Time) bool {
+	return event.Start.Before(end) && start.Before(event.End)
+}
+
+// Calendar : events ordered by start, safe for concurrent reads
+type Calendar struct {
+	events []Event
+}
+
+// Overlapping returns the events overlapping [start, end) in start order
+func (calendar *Calendar) Overlapping(start time.Time, end time.Time) []Event {
+	var events []Event
+	for _, event := range calendar.events {
+		if !event.Start.Before(end) {
+			break
+		}
+		if event.Overlaps(start, end) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (calendar *Calendar) GetEvents() []Event {
+	events := make([]Event, len(calendar.events))
+	copy(events, calendar.events)
+	return events
+}
+
+// New creates the calendar, every event needs a name and an end after its start
+func New(events ...Event) (*Calendar, error) {
+	sorted := make([]Event, len(events))
+	copy(sorted, events)
+	for _, event := range sorted {
+		if event.Name == "" || !event.End.After(event.Start) {
+			return nil, fmt.Errorf("%w %q from %v to %v", ErrInvalidEvent, event.Name, event.Start, event.End)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+	return &Calendar{events: sorted}, nil
+}
+
+// Spec : JSON form of the calendar file
+type Spec struct {
+	Location string        `json:"location,omitempty"`
+	Events   []EventSpec   `json:"events,omitempty"`
+	Holidays []HolidaySpec `json:"holidays,omitempty"`
+}
+
+type EventSpec struct {
+	Name  string    `json:"name"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type HolidaySpec struct {
+	Name string `json:"name"`
+	Date string `json:"date"`
+}
+
+func (spec Spec) Build() (*Calendar, error) {
+	location := time.UTC
+	if spec.Location != "" {
+		var err error
+		if location, err = time.LoadLocation(spec.Location); err != nil {
+			return nil, fmt.Errorf("%w: location %v", ErrInvalidEvent, err)
+		}
+	}
+	var events []Event
+	for _, eventSpec := range spec.Events {
+		events = append(events, Event{Name: eventSpec.Name, Start: eventSpec.Start, End: eventSpec.End})
+	}
+	for _, holidaySpec := range spec.Holidays {
+		date, err := time.ParseInLocation("2006-01-02", holidaySpec.Date, location)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: date %v", ErrInvalidEvent, holidaySpec.Name, err)
+		}
+		events = append(events, Event{Name: holidaySpec.Name, Start: date, End: date.AddDate(0, 0, 1)})
+	}
+	return New(events...)
+}
+
+// Parse builds the calendar from the JSON document
+func Parse(data []byte) (*Calendar, error) {
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	return spec.Build()
+}
+
+// Load reads the calendar file
+func Load(path string) (*Calendar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
@@ -0,0 +1,60 @@
+package calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOverlapping(t *testing.T) {
+	final := Event{Name: "Cup Final", Start: time.Date(2021, 5, 15, 12, 0, 0, 0, time.UTC), End: time.Date(2021, 5, 15, 20, 0, 0, 0, time.UTC)}
+	concert := Event{Name: "Concert", Start: time.Date(2021, 5, 15, 19, 0, 0, 0, time.UTC), End: time.Date(2021, 5, 15, 23, 0, 0, 0, time.UTC)}
+	calendar, err := New(concert, final)
+	if err != nil {
+		t.Fatalf("calendar failed %v", err)
+	}
+	events := calendar.Overlapping(time.Date(2021, 5, 15, 10, 0, 0, 0, time.UTC), time.Date(2021, 5, 15, 21, 0, 0, 0, time.UTC))
+	if len(events) != 2 || events[0].Name != "Cup Final" || events[1].Name != "Concert" {
+		t.Errorf("unexpected events %v", events)
+	}
+	// the end is exclusive
+	if events := calendar.Overlapping(time.Date(2021, 5, 15, 8, 0, 0, 0, time.UTC), final.Start); len(events) != 0 {
+		t.Errorf("stay ending at the start must not overlap %v", events)
+	}
+	if _, err := New(Event{Name: "Backwards", Start: final.End, End: final.Start}); !errors.Is(err, ErrInvalidEvent) {
+		t.Errorf("expected ErrInvalidEvent, got %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	calendar, err := Parse([]byte(`{
+  "location": "America/New_York",
+  "events": [{"name": "Cup Final", "start": "2021-05-15T12:00:00Z", "end": "2021-05-15T20:00:00Z"}],
+  "holidays": [{"name": "Independence Day", "date": "2021-07-04"}]
+}`))
+	if err != nil {
+		t.Skipf("calendar not parsed, time zone data may be missing: %v", err)
+	}
+	events := calendar.GetEvents()
+	if len(events) != 2 || events[1].Name != "Independence Day" {
+		t.Fatalf("unexpected events %v", events)
+	}
+	// the holiday is the local date, 04:00 UTC is midnight in New York
+	if !events[1].Start.Equal(time.Date(2021, 7, 4, 4, 0, 0, 0, time.UTC)) || events[1].End.Sub(events[1].Start) != 24*time.Hour {
+		t.Errorf("unexpected holiday window %v %v", events[1].Start, events[1].End)
+	}
+	if _, err := Parse([]byte(`{"holidays": [{"name": "Christmas", "date": "25/12/2021"}]}`)); !errors.Is(err, ErrInvalidEvent) {
+		t.Errorf("expected ErrInvalidEvent, got %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	calendar, err := Load("testdata/stadium.json")
+	if err != nil {
+		t.Skipf("calendar not loaded, time zone data may be missing: %v", err)
+	}
+	events := calendar.GetEvents()
+	if len(events) != 4 || events[2].Name != "Christmas Day" || events[3].Name != "Boxing Day" {
+		t.Errorf("events must be ordered by start %v", events)
+	}
+}
@@ -0,0 +1,147 @@
+// Package charges adjusts the tariff cost before it is billed: surcharges such as a
+// facility fee, taxes as a percentage of the net amount and cash rounding of the gross.
+// Steps run in pipeline order, a tax only covers the surcharges added before it.
+package charges
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"math/big"
+)
+
+var ErrInvalidRate = errors.New("invalid tax rate")
+
+// CurrencyError : a surcharge or rounding increment is not in the currency it is applied to,
+// matches money.ErrCurrencyMismatch
+type CurrencyError struct {
+	Step     string
+	Amount   money.Money
+	Currency string
+}
+
+func (currencyError *CurrencyError) Error() string {
+	return fmt.Sprintf("%s: %v applied to %s", currencyError.Step, currencyError.Amount, currencyError.Currency)
+}
+
+func (currencyError *CurrencyError) Is(target error) bool {
+	return target == money.ErrCurrencyMismatch
+}
+
+// Line : one adjustment made by a step, Kind is one of the slot receipt line kinds
+type Line struct {
+	Kind        string
+	Description string
+	Amount      money.Money
+}
+
+// Bill : the tariff cost after the pipeline, Gross is Net + Tax + Rounding
+type Bill struct {
+	Tariff   money.Money
+	Net      money.Money // tariff cost and surcharges
+	Tax      money.Money
+	Rounding money.Money
+	Lines    []Line
+}
+
+func (bill Bill) Gross() money.Money {
+	return bill.Net.Add(bill.Tax).Add(bill.Rounding)
+}
+
+// Step : one adjustment of the pipeline
+type Step interface {
+	Apply(bill *Bill)
+}
+
+// Surcharge : fixed amount added to the net, e.g. an airport facility fee
+type Surcharge struct {
+	name   string
+	amount money.Money
+}
+
+func (surcharge *Surcharge) Apply(bill *Bill) {
+	bill.Net = bill.Net.Add(surcharge.amount)
+	bill.Lines = append(bill.Lines, Line{Kind: slot.LineSurcharge, Description: surcharge.name, Amount: surcharge.amount})
+}
+
+func NewSurcharge(name string, amount money.Money) *Surcharge {
+	return &Surcharge{name: name, amount: amount}
+}
+
+// Tax : percentage of the net amount, rounded half up to the minor unit
+type Tax struct {
+	name    string
+	percent string
+	rate    *big.Rat
+}
+
+func (tax *Tax) Apply(bill *Bill) {
+	amount := bill.Net.MulRat(tax.rate.Num().Int64(), tax.rate.Denom().Int64(), money.RoundHalfUp)
+	bill.Tax = bill.Tax.Add(amount)
+	bill.Lines = append(bill.Lines, Line{Kind: slot.LineTax, Description: fmt.Sprintf("%s %s%%", tax.name, tax.percent), Amount: amount})
+}
+
+// NewTax takes the percentage as an exact decimal, NewTax("VAT", "20") or NewTax("Sales tax", "8.875")
+func NewTax(name string, percent string) (*Tax, error) {
+	rate, ok := new(big.Rat).SetString(percent)
+	if !ok || rate.Sign() < 0 || !rate.Num().IsInt64() || !rate.Denom().IsInt64() {
+		return nil, fmt.Errorf("%w %q for %s", ErrInvalidRate, percent, name)
+	}
+	return &Tax{name: name, percent: percent, rate: rate.Quo(rate, big.NewRat(100, 1))}, nil
+}
+
+// Rounding : rounds the gross amount to a multiple of the increment, e.g. 0.05 CHF
+// for cash payments or a whole unit, the difference is its own line
+type Rounding struct {
+	increment money.Money
+	mode      money.RoundingMode
+}
+
+func (rounding *Rounding) Apply(bill *Bill) {
+	gross := bill.Gross()
+	adjustment := gross.RoundTo(rounding.increment, rounding.mode).Sub(gross)
+	if adjustment.IsZero() {
+		return
+	}
+	bill.Rounding = bill.Rounding.Add(adjustment)
+	bill.Lines = append(bill.Lines, Line{Kind: slot.LineRounding, Description: "Rounding", Amount: adjustment})
+}
+
+func NewRounding(increment money.Money, mode money.RoundingMode) *Rounding {
+	return &Rounding{increment: increment, mode: mode}
+}
+
+// Pipeline : steps applied in order to the tariff cost
+type Pipeline []Step
+
+func NewPipeline(steps ...Step) Pipeline {
+	return steps
+}
+
+// CheckCurrency requires the surcharges and rounding increments in the currency, taxes apply to any
+func (pipeline Pipeline) CheckCurrency(currency string) error {
+	for _, step := range pipeline {
+		switch step := step.(type) {
+		case *Surcharge:
+			if step.amount.Currency() != currency {
+				return &CurrencyError{Step: step.name, Amount: step.amount, Currency: currency}
+			}
+		case *Rounding:
+			if step.increment.Currency() != currency {
+				return &CurrencyError{Step: "Rounding", Amount: step.increment, Currency: currency}
+			}
+		}
+	}
+	return nil
+}
+
+// Apply runs the steps over the tariff cost, an empty pipeline bills the cost as is
+func (pipeline Pipeline) Apply(cost money.Money) Bill {
+	zero := money.New(0, cost.Currency())
+	bill := Bill{Tariff: cost, Net: cost, Tax: zero, Rounding: zero}
+	for _, step := range pipeline {
+		step.Apply(&bill)
+	}
+	return bill
+}
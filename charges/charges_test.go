@@ -0,0 +1,87 @@
+package charges
+
+import (
+	"errors"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"testing"
+)
+
+func chf(amount int64) money.Money {
+	return money.New(amount, "CHF")
+}
+
+func TestPipeline(t *testing.T) {
+	vat, err := NewTax("VAT", "8.1")
+	if err != nil {
+		t.Fatalf("tax failed %v", err)
+	}
+	pipeline := NewPipeline(NewSurcharge("Facility fee", chf(200)), vat, NewRounding(chf(5), money.RoundHalfUp))
+	bill := pipeline.Apply(chf(1034))
+	// net 12.34, tax 0.99954 rounds to 1.00, gross 13.34 rounds to 13.35
+	if bill.Tariff != chf(1034) || bill.Net != chf(1234) || bill.Tax != chf(100) || bill.Rounding != chf(1) || bill.Gross() != chf(1335) {
+		t.Errorf("unexpected bill %+v", bill)
+	}
+	kinds := []string{slot.LineSurcharge, slot.LineTax, slot.LineRounding}
+	if len(bill.Lines) != len(kinds) {
+		t.Fatalf("unexpected lines %v", bill.Lines)
+	}
+	for i, kind := range kinds {
+		if bill.Lines[i].Kind != kind {
+			t.Errorf("line %d kind %s, expected %s", i, bill.Lines[i].Kind, kind)
+		}
+	}
+	if bill.Lines[1].Description != "VAT 8.1%" {
+		t.Errorf("unexpected tax line %v", bill.Lines[1])
+	}
+}
+
+func TestTaxOrder(t *testing.T) {
+	salesTax, _ := NewTax("Sales tax", "10")
+	fee := NewSurcharge("Facility fee", money.New(500, "USD"))
+	before := NewPipeline(fee, salesTax).Apply(money.New(2000, "USD"))
+	after := NewPipeline(salesTax, fee).Apply(money.New(2000, "USD"))
+	if before.Tax != money.New(250, "USD") || after.Tax != money.New(200, "USD") {
+		t.Errorf("tax must cover only earlier surcharges %v %v", before.Tax, after.Tax)
+	}
+}
+
+func TestWholeUnitRounding(t *testing.T) {
+	bill := NewPipeline(NewRounding(money.New(100, "USD"), money.RoundDown)).Apply(money.New(1999, "USD"))
+	if bill.Gross() != money.New(1900, "USD") || bill.Rounding != money.New(-99, "USD") {
+		t.Errorf("unexpected rounding %+v", bill)
+	}
+	bill = NewPipeline(NewRounding(money.New(100, "USD"), money.RoundDown)).Apply(money.New(2000, "USD"))
+	if len(bill.Lines) != 0 {
+		t.Errorf("no rounding line expected %v", bill.Lines)
+	}
+}
+
+func TestEmptyPipeline(t *testing.T) {
+	var pipeline Pipeline
+	bill := pipeline.Apply(money.New(400, "JPY"))
+	if bill.Gross() != money.New(400, "JPY") || bill.Net != bill.Gross() || !bill.Tax.IsZero() || bill.Tax.Currency() != "JPY" {
+		t.Errorf("unexpected bill %+v", bill)
+	}
+}
+
+func TestInvalidTax(t *testing.T) {
+	for _, percent := range []string{"", "abc", "-5"} {
+		if _, err := NewTax("VAT", percent); !errors.Is(err, ErrInvalidRate) {
+			t.Errorf("expected ErrInvalidRate for %q, got %v", percent, err)
+		}
+	}
+}
+
+func TestCheckCurrency(t *testing.T) {
+	vat, _ := NewTax("VAT", "8.1")
+	pipeline := NewPipeline(NewSurcharge("Facility fee", chf(200)), vat, NewRounding(chf(5), money.RoundHalfUp))
+	if err := pipeline.CheckCurrency("CHF"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	var currencyError *CurrencyError
+	if err := pipeline.CheckCurrency("EUR"); !errors.Is(err, money.ErrCurrencyMismatch) || !errors.As(err, &currencyError) ||
+		currencyError.Step != "Facility fee" || currencyError.Currency != "EUR" {
+		t.Errorf("expected a currency error, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package parking
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock supplies the time used for entry and exit of vehicles
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock : wall clock of the system
+type RealClock struct{}
+
+func (realClock RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func NewRealClock() Clock {
+	return RealClock{}
+}
+
+// FakeClock : manually controlled clock, used to replay and test stays deterministically
+type FakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (fakeClock *FakeClock) Now() time.Time {
+	fakeClock.mutex.Lock()
+	defer fakeClock.mutex.Unlock()
+	return fakeClock.now
+}
+
+func (fakeClock *FakeClock) Set(now time.Time) {
+	fakeClock.mutex.Lock()
+	defer fakeClock.mutex.Unlock()
+	fakeClock.now = now
+}
+
+func (fakeClock *FakeClock) Advance(duration time.Duration) {
+	fakeClock.mutex.Lock()
+	defer fakeClock.mutex.Unlock()
+	fakeClock.now = fakeClock.now.Add(duration)
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
@@ -0,0 +1,30 @@
+package parking
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	if clock.Now() != start {
+		t.Errorf("fake clock start failed")
+	}
+	clock.Advance(time.Hour * 25)
+	if !clock.Now().Equal(start.Add(time.Hour * 25)) {
+		t.Errorf("fake clock advance failed")
+	}
+	clock.Set(start)
+	if clock.Now() != start {
+		t.Errorf("fake clock set failed")
+	}
+}
+
+func TestRealClock(t *testing.T) {
+	before := time.Now()
+	now := NewRealClock().Now()
+	if now.Before(before) || now.After(time.Now()) {
+		t.Errorf("real clock failed")
+	}
+}
@@ -0,0 +1,653 @@
+// Package config loads parking lot layout and tariffs from a JSON file, e.g.
+//
+//	{
+//	  "vehicles": [
+//	    {
+//	      "type": "Scooter",
+//	      "slots": 200,
+//	      "tariff": {
+//	        "matcher": "Single",
+//	        "models": [
+//	          {"model": "HourInterval", "price": "0 USD", "start": "0h", "end": "1h"},
+//	          {"model": "EveryDay", "price": "80 USD", "start": "0h"}
+//	        ]
+//	      }
+//	    }
+//	  ],
+//	  "charges": [
+//	    {"type": "Surcharge", "name": "Facility fee", "amount": "2 USD"},
+//	    {"type": "Tax", "name": "Sales tax", "percent": "8.875"},
+//	    {"type": "Rounding", "increment": "0.05 USD", "mode": "HalfUp"}
+//	  ]
+//	}
+//
+// Prices are exact decimal amounts with their currency code. Ranges are Go durations,
+// a missing start is 0 and a missing end is unbounded. TimeOfDay models take "bands"
+// of clock times instead of a range, e.g.
+// {"name": "Night", "from": "18:00", "to": "08:00", "days": ["Weekdays"], "price": "3 USD"}. Charges are applied in order
+// after the tariff of every vehicle type. A vehicle "event" prices stays overlapping an
+// event of the lot "calendar", see the calendar package for its schema. Tariff "caps" limit
+// the cost per day and per stay, {"daily": "30 USD", "period": "CalendarDay", "stay": "200 USD"},
+// tariff "grace" periods are Go durations, {"free": "10m", "tolerance": "5m"}. Duration based
+// models take a billing "granularity", {"increment": "15m", "rounding": "Ceil", "minimum": "1h"}.
+// Matchers are Single, Multiple, Cheapest and Max, a "Composite" model nests another matcher,
+// {"model": "Composite", "tariff": {"matcher": "Single", "models": [...]}}. A vehicle
+// "lostTicket" tariff bills exits without the ticket and its "overflow" rules let it park in
+// larger slots when its own are taken, {"slots": "Suv", "bill": "Vehicle", "reserve": 5}.
+// Vehicle types other than Scooter, Suv and Truck are registered by the lot "vehicleTypes",
+// {"name": "Bicycle", "size": "Small"}.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hbkkanna/parking"
+	"github.com/hbkkanna/parking/calendar"
+	"github.com/hbkkanna/parking/charges"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"github.com/hbkkanna/parking/tariff"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownVehicle   = slot.ErrUnknownVehicleType
+	ErrDuplicateVehicle = errors.New("duplicate vehicle type")
+	ErrInvalidValue     = errors.New("invalid value")
+)
+
+// buildMutex serializes building configs, the vehicle types a config registers are not
+// reused by another config until it is built or rolled back
+var buildMutex sync.Mutex
+
+// Error : problem in the config file, with the line and the field path it was found at
+type Error struct {
+	Line  int
+	Field string
+	Err   error
+}
+
+func (configError *Error) Error() string {
+	if configError.Field == "" {
+		return fmt.Sprintf("config: line %d: %v", configError.Line, configError.Err)
+	}
+	return fmt.Sprintf("config: line %d: %s: %v", configError.Line, configError.Field, configError.Err)
+}
+
+func (configError *Error) Unwrap() error {
+	return configError.Err
+}
+
+// LotConfig : an optional currency requires every tariff of the lot to be priced in it
+type LotConfig struct {
+	Currency     string              `json:"currency,omitempty"`
+	VehicleTypes []VehicleTypeConfig `json:"vehicleTypes,omitempty"`
+	Vehicles     []VehicleConfig     `json:"vehicles"`
+	Charges      []ChargeConfig      `json:"charges,omitempty"`
+	Calendar     *calendar.Spec      `json:"calendar,omitempty"`
+}
+
+// charge step types
+const (
+	SurchargeCharge = "Surcharge"
+	TaxCharge       = "Tax"
+	RoundingCharge  = "Rounding"
+)
+
+// roundingModes : names of the money rounding modes, HalfUp when omitted
+var roundingModes = map[string]money.RoundingMode{
+	"":         money.RoundHalfUp,
+	"HalfUp":   money.RoundHalfUp,
+	"HalfEven": money.RoundHalfEven,
+	"Down":     money.RoundDown,
+	"Up":       money.RoundUp,
+}
+
+// ChargeConfig : one step of the post-tariff pipeline, the fields used depend on the type
+type ChargeConfig struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name,omitempty"`
+	Amount    money.Money `json:"amount,omitempty"`
+	Percent   string      `json:"percent,omitempty"`
+	Increment money.Money `json:"increment,omitempty"`
+	Mode      string      `json:"mode,omitempty"`
+}
+
+// VehicleTypeConfig : vehicle type registered by the config, size is Small, Medium or Large
+type VehicleTypeConfig struct {
+	Name     string            `json:"name"`
+	Size     string            `json:"size"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+type VehicleConfig struct {
+	Type   string       `json:"type"`
+	Slots  int          `json:"slots"`
+	Tariff TariffConfig `json:"tariff"`
+	Event  *EventConfig `json:"event,omitempty"`
+	// LostTicket is the penalty tariff of exits without the ticket
+	LostTicket *TariffConfig `json:"lostTicket,omitempty"`
+	// Overflow lists the larger slot types the vehicle may use when its slots are taken
+	Overflow []OverflowConfig `json:"overflow,omitempty"`
+}
+
+// OverflowConfig : slot type by name, the tariff that bills the stay, Vehicle or Slot, and the
+// slots of the slot type kept free for its own vehicles
+type OverflowConfig struct {
+	Slots   string `json:"slots"`
+	Bill    string `json:"bill,omitempty"`
+	Reserve int    `json:"reserve,omitempty"`
+}
+
+// EventConfig : pricing of stays overlapping an event of the lot calendar, an alternative
+// tariff, a surcharge for every overlapped event or both
+type EventConfig struct {
+	Tariff    *TariffConfig `json:"tariff,omitempty"`
+	Surcharge money.Money   `json:"surcharge,omitempty"`
+}
+
+// TariffConfig and ModelConfig share the JSON schema tariffs are serialized with
+type TariffConfig = tariff.TariffSpec
+
+type ModelConfig = tariff.ModelSpec
+
+// Load reads the config file and builds the parking configs
+func Load(path string) ([]*parking.ParkingConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse builds the parking configs from the JSON document
+func Parse(data []byte) ([]*parking.ParkingConfig, error) {
+	positions := newPositions(data)
+	var lotConfig LotConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&lotConfig); err != nil {
+		return nil, decodeError(positions, err)
+	}
+	buildMutex.Lock()
+	defer buildMutex.Unlock()
+	builder := &builder{positions: positions}
+	configs, err := builder.build(lotConfig)
+	if err != nil {
+		// a rejected config leaves no vehicle types behind, the corrected config registers them
+		builder.unregisterVehicleTypes()
+		return nil, err
+	}
+	return configs, nil
+}
+
+func decodeError(positions *positions, err error) error {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxError):
+		return &Error{Line: positions.offsetLine(syntaxError.Offset), Err: err}
+	case errors.As(err, &typeError):
+		return &Error{Line: positions.offsetLine(typeError.Offset), Field: fieldPath(typeError.Field), Err: err}
+	case errors.Is(err, money.ErrInvalidAmount):
+		// the decoder does not report where a value failed its own unmarshaling
+		var paths []string
+		for path := range positions.offsets {
+			if strings.HasSuffix(path, ".price") || strings.HasSuffix(path, ".amount") || strings.HasSuffix(path, ".increment") ||
+				strings.HasSuffix(path, ".surcharge") || strings.HasSuffix(path, ".daily") || strings.HasSuffix(path, ".stay") {
+				paths = append(paths, path)
+			}
+		}
+		sort.Slice(paths, func(i, j int) bool {
+			return positions.offsets[paths[i]] < positions.offsets[paths[j]]
+		})
+		for _, path := range paths {
+			var value money.Money
+			if json.Unmarshal(positions.raw(path), &value) != nil {
+				return &Error{Line: positions.line(path), Field: path, Err: err}
+			}
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		// the name may be a valid field elsewhere, the decoder stops at the first object that
+		// does not declare it
+		var paths []string
+		for path := range positions.offsets {
+			if path == field || strings.HasSuffix(path, "."+field) {
+				paths = append(paths, path)
+			}
+		}
+		sort.Slice(paths, func(i, j int) bool {
+			return positions.offsets[paths[i]] < positions.offsets[paths[j]]
+		})
+		for _, path := range paths {
+			if !declaresField(strings.TrimSuffix(strings.TrimSuffix(path, field), "."), field) {
+				return &Error{Line: positions.line(path), Field: path, Err: err}
+			}
+		}
+		return &Error{Line: 1, Field: field, Err: err}
+	}
+	return &Error{Line: positions.offsetLine(int64(len(positions.data))), Err: err}
+}
+
+// fieldPath writes array indices of a decoder field, vehicles.0.slots, as vehicles[0].slots
+func fieldPath(field string) string {
+	parts := strings.Split(field, ".")
+	path := ""
+	for _, part := range parts {
+		if _, err := strconv.Atoi(part); err == nil {
+			path += "[" + part + "]"
+		} else if path == "" {
+			path = part
+		} else {
+			path += "." + part
+		}
+	}
+	return path
+}
+
+// declaresField reports whether the object at the path of a LotConfig document has the field,
+// matched ignoring case like the decoder does. Paths it can not follow declare every field.
+func declaresField(path string, field string) bool {
+	valueType := reflect.TypeOf(LotConfig{})
+	for _, part := range splitPath(path) {
+		if valueType = memberType(valueType, part); valueType == nil {
+			return true
+		}
+	}
+	return memberType(valueType, field) != nil
+}
+
+// memberType returns the type of the field, map value or element, [i] parts are elements
+func memberType(valueType reflect.Type, part string) reflect.Type {
+	for valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	switch valueType.Kind() {
+	case reflect.Slice, reflect.Array:
+		if strings.HasPrefix(part, "[") {
+			return valueType.Elem()
+		}
+	case reflect.Map:
+		return valueType.Elem()
+	case reflect.Struct:
+		for i := 0; i < valueType.NumField(); i++ {
+			structField := valueType.Field(i)
+			name := strings.Split(structField.Tag.Get("json"), ",")[0]
+			if name == "-" || (structField.PkgPath != "" && !structField.Anonymous) {
+				continue
+			}
+			if structField.Anonymous && name == "" {
+				if embedded := memberType(structField.Type, part); embedded != nil {
+					return embedded
+				}
+				continue
+			}
+			if name == "" {
+				name = structField.Name
+			}
+			if strings.EqualFold(name, part) {
+				return structField.Type
+			}
+		}
+	}
+	return nil
+}
+
+// splitPath splits vehicles[0].tariff into vehicles, [0] and tariff
+func splitPath(path string) []string {
+	var parts []string
+	for _, key := range strings.Split(path, ".") {
+		for {
+			index := strings.Index(key, "[")
+			if index < 0 {
+				break
+			}
+			if index > 0 {
+				parts = append(parts, key[:index])
+			}
+			end := strings.Index(key, "]")
+			if end < index {
+				break
+			}
+			parts = append(parts, key[index:end+1])
+			key = key[end+1:]
+		}
+		if key != "" {
+			parts = append(parts, key)
+		}
+	}
+	return parts
+}
+
+type builder struct {
+	positions  *positions
+	registered []int
+}
+
+func (builder *builder) fail(field string, err error) error {
+	return &Error{Line: builder.positions.line(field), Field: field, Err: err}
+}
+
+func (builder *builder) build(lotConfig LotConfig) ([]*parking.ParkingConfig, error) {
+	var configs []*parking.ParkingConfig
+	pipeline, err := builder.buildCharges(lotConfig.Charges)
+	if err != nil {
+		return nil, err
+	}
+	if err := builder.registerVehicleTypes(lotConfig.VehicleTypes); err != nil {
+		return nil, err
+	}
+	var events *calendar.Calendar
+	if lotConfig.Calendar != nil {
+		if events, err = lotConfig.Calendar.Build(); err != nil {
+			return nil, builder.fail("calendar", err)
+		}
+	}
+	seen := make(map[int]bool)
+	for i, vehicleConfig := range lotConfig.Vehicles {
+		field := fmt.Sprintf("vehicles[%d]", i)
+		vehicleType, ok := slot.GetVehicleTypeByName(vehicleConfig.Type)
+		if !ok {
+			return nil, builder.fail(field+".type", fmt.Errorf("%w %q", ErrUnknownVehicle, vehicleConfig.Type))
+		}
+		if seen[vehicleType] {
+			return nil, builder.fail(field+".type", fmt.Errorf("%w %q", ErrDuplicateVehicle, vehicleConfig.Type))
+		}
+		seen[vehicleType] = true
+		if vehicleConfig.Slots <= 0 {
+			return nil, builder.fail(field+".slots", fmt.Errorf("%w: slot count %d must be positive", ErrInvalidValue, vehicleConfig.Slots))
+		}
+		vehicleTariff, err := builder.buildTariff(field+".tariff", vehicleConfig.Tariff)
+		if err != nil {
+			return nil, err
+		}
+		if lotConfig.Currency != "" && vehicleTariff.GetCurrency() != lotConfig.Currency {
+			return nil, builder.fail(field+".tariff", fmt.Errorf("%w: tariff in %s, lot in %s", money.ErrCurrencyMismatch,
+				vehicleTariff.GetCurrency(), lotConfig.Currency))
+		}
+		if vehicleConfig.Event != nil {
+			if vehicleTariff, err = builder.buildEventTariff(field+".event", *vehicleConfig.Event, vehicleTariff, events); err != nil {
+				return nil, err
+			}
+		}
+		if err := builder.checkChargeCurrency(lotConfig.Charges, vehicleTariff.GetCurrency()); err != nil {
+			return nil, err
+		}
+		parkingConfig := parking.NewParkingConfig(vehicleType, vehicleConfig.Slots, vehicleTariff)
+		if err := parkingConfig.SetCharges(pipeline); err != nil {
+			return nil, builder.fail("charges", err)
+		}
+		if vehicleConfig.LostTicket != nil {
+			penalty, err := builder.buildTariff(field+".lostTicket", *vehicleConfig.LostTicket)
+			if err != nil {
+				return nil, err
+			}
+			if err := parkingConfig.SetLostTicketTariff(penalty); err != nil {
+				return nil, builder.fail(field+".lostTicket", err)
+			}
+		}
+		configs = append(configs, parkingConfig)
+	}
+	// overflow rules may name vehicle types listed after them
+	for i, vehicleConfig := range lotConfig.Vehicles {
+		for j, overflowConfig := range vehicleConfig.Overflow {
+			field := fmt.Sprintf("vehicles[%d].overflow[%d]", i, j)
+			slotType, ok := slot.GetVehicleTypeByName(overflowConfig.Slots)
+			if !ok {
+				return nil, builder.fail(field+".slots", fmt.Errorf("%w %q", ErrUnknownVehicle, overflowConfig.Slots))
+			}
+			if !seen[slotType] {
+				return nil, builder.fail(field+".slots", fmt.Errorf("%w: the lot has no %s slots", ErrInvalidValue, overflowConfig.Slots))
+			}
+			overflow := parking.Overflow{SlotType: slotType, Bill: overflowConfig.Bill, Reserve: overflowConfig.Reserve}
+			if err := configs[i].AllowOverflow(overflow); err != nil {
+				return nil, builder.fail(field, err)
+			}
+		}
+	}
+	return configs, nil
+}
+
+// registerVehicleTypes adds the vehicle types of the config to the slot registry, a type that is
+// already registered with the same size is reused so a config can be loaded more than once.
+// The types it adds are kept in registered until the whole config is built.
+func (builder *builder) registerVehicleTypes(vehicleTypeConfigs []VehicleTypeConfig) error {
+	for i, vehicleTypeConfig := range vehicleTypeConfigs {
+		field := fmt.Sprintf("vehicleTypes[%d]", i)
+		size, err := slot.ParseSizeClass(vehicleTypeConfig.Size)
+		if err != nil {
+			return builder.fail(field+".size", err)
+		}
+		if registered, err := slot.LookupVehicleType(vehicleTypeConfig.Name); err == nil {
+			if registered.Size != size {
+				return builder.fail(field+".name", fmt.Errorf("%w %q registered as %v", slot.ErrDuplicateVehicleType,
+					vehicleTypeConfig.Name, registered.Size))
+			}
+			continue
+		}
+		vehicleType, err := slot.RegisterVehicleType(vehicleTypeConfig.Name, size, vehicleTypeConfig.Metadata)
+		if err != nil {
+			return builder.fail(field+".name", err)
+		}
+		builder.registered = append(builder.registered, vehicleType)
+	}
+	return nil
+}
+
+// unregisterVehicleTypes removes the vehicle types the config added
+func (builder *builder) unregisterVehicleTypes() {
+	for _, vehicleType := range builder.registered {
+		_ = slot.UnregisterVehicleType(vehicleType)
+	}
+	builder.registered = nil
+}
+
+func (builder *builder) buildEventTariff(field string, eventConfig EventConfig, base tariff.Tariff,
+	events *calendar.Calendar) (tariff.Tariff, error) {
+	if events == nil {
+		return nil, builder.fail(field, fmt.Errorf("%w: event pricing needs a lot calendar", ErrInvalidValue))
+	}
+	if eventConfig.Surcharge.IsNegative() {
+		return nil, builder.fail(field+".surcharge", fmt.Errorf("%w: surcharge %v must not be negative", ErrInvalidValue, eventConfig.Surcharge))
+	}
+	var eventChain tariff.Tariff
+	if eventConfig.Tariff != nil {
+		var err error
+		if eventChain, err = builder.buildTariff(field+".tariff", *eventConfig.Tariff); err != nil {
+			return nil, err
+		}
+	}
+	eventTariff, err := tariff.NewEventTariff(base, events, eventChain, eventConfig.Surcharge)
+	if err != nil {
+		return nil, builder.fail(field, err)
+	}
+	return eventTariff, nil
+}
+
+func (builder *builder) buildCharges(chargeConfigs []ChargeConfig) (charges.Pipeline, error) {
+	var steps []charges.Step
+	for i, chargeConfig := range chargeConfigs {
+		field := fmt.Sprintf("charges[%d]", i)
+		switch chargeConfig.Type {
+		case SurchargeCharge:
+			if chargeConfig.Amount.IsNegative() {
+				return nil, builder.fail(field+".amount", fmt.Errorf("%w: amount %v must not be negative", ErrInvalidValue, chargeConfig.Amount))
+			}
+			steps = append(steps, charges.NewSurcharge(chargeConfig.Name, chargeConfig.Amount))
+		case TaxCharge:
+			tax, err := charges.NewTax(chargeConfig.Name, chargeConfig.Percent)
+			if err != nil {
+				return nil, builder.fail(field+".percent", err)
+			}
+			steps = append(steps, tax)
+		case RoundingCharge:
+			mode, ok := roundingModes[chargeConfig.Mode]
+			if !ok {
+				return nil, builder.fail(field+".mode", fmt.Errorf("%w: unknown rounding mode %q", ErrInvalidValue, chargeConfig.Mode))
+			}
+			if chargeConfig.Increment.Amount() <= 0 {
+				return nil, builder.fail(field+".increment", fmt.Errorf("%w: increment %v must be positive", ErrInvalidValue, chargeConfig.Increment))
+			}
+			steps = append(steps, charges.NewRounding(chargeConfig.Increment, mode))
+		default:
+			return nil, builder.fail(field+".type", fmt.Errorf("%w: unknown charge type %q", ErrInvalidValue, chargeConfig.Type))
+		}
+	}
+	return charges.NewPipeline(steps...), nil
+}
+
+// checkChargeCurrency requires the surcharges and rounding increments in the tariff currency
+func (builder *builder) checkChargeCurrency(chargeConfigs []ChargeConfig, currency string) error {
+	for i, chargeConfig := range chargeConfigs {
+		field, amount := fmt.Sprintf("charges[%d].amount", i), chargeConfig.Amount
+		if chargeConfig.Type == RoundingCharge {
+			field, amount = fmt.Sprintf("charges[%d].increment", i), chargeConfig.Increment
+		}
+		if chargeConfig.Type != TaxCharge && amount.Currency() != currency {
+			return builder.fail(field, fmt.Errorf("%w: %v in tariff currency %s", money.ErrCurrencyMismatch, amount, currency))
+		}
+	}
+	return nil
+}
+
+func (builder *builder) buildTariff(field string, tariffConfig TariffConfig) (tariff.Tariff, error) {
+	matcher, err := tariff.NewMatcher(tariffConfig.Matcher)
+	if err != nil {
+		return nil, builder.fail(field+".matcher", err)
+	}
+	if len(tariffConfig.Models) == 0 {
+		return nil, builder.fail(field+".models", fmt.Errorf("%w: at least one model is required", ErrInvalidValue))
+	}
+	for i, modelConfig := range tariffConfig.Models {
+		if modelConfig.Model == tariff.CompositeModel {
+			nested, err := builder.buildComposite(fmt.Sprintf("%s.models[%d]", field, i), modelConfig)
+			if err != nil {
+				return nil, err
+			}
+			if tariffConfig.Currency != "" && nested.GetCurrency() != tariffConfig.Currency {
+				return nil, builder.fail(fmt.Sprintf("%s.models[%d].tariff", field, i), fmt.Errorf("%w: priced in %s, tariff currency %s",
+					money.ErrCurrencyMismatch, nested.GetCurrency(), tariffConfig.Currency))
+			}
+			matcher.Append(nested)
+			continue
+		}
+		if tariffConfig.Currency != "" && modelConfig.Price.Currency() != tariffConfig.Currency {
+			return nil, builder.fail(fmt.Sprintf("%s.models[%d].price", field, i), fmt.Errorf("%w: price %v in tariff currency %s",
+				money.ErrCurrencyMismatch, modelConfig.Price, tariffConfig.Currency))
+		}
+		model, err := builder.buildModel(fmt.Sprintf("%s.models[%d]", field, i), modelConfig)
+		if err != nil {
+			return nil, err
+		}
+		matcher.Append(model)
+	}
+	for _, issue := range tariff.Validate(matcher) {
+		if !issue.IsError() {
+			continue
+		}
+		if issue.Model < 0 {
+			return nil, builder.fail(field+".models", fmt.Errorf("%w: %s", ErrInvalidValue, issue))
+		}
+		return nil, builder.fail(fmt.Sprintf("%s.models[%d]", field, issue.Model), fmt.Errorf("%w: %s", ErrInvalidValue, issue))
+	}
+	var vehicleTariff tariff.Tariff = matcher
+	if tariffConfig.Caps != nil {
+		if vehicleTariff, err = tariff.NewCappedTariff(vehicleTariff, tariffConfig.Caps.Build()); err != nil {
+			return nil, builder.fail(field+".caps", err)
+		}
+	}
+	if tariffConfig.Grace != nil {
+		grace, err := tariffConfig.Grace.Build()
+		if err != nil {
+			return nil, builder.fail(field+".grace", err)
+		}
+		if vehicleTariff, err = tariff.NewGraceTariff(vehicleTariff, grace); err != nil {
+			return nil, builder.fail(field+".grace", err)
+		}
+	}
+	return vehicleTariff, nil
+}
+
+// buildComposite builds the matcher nested in a Composite model
+func (builder *builder) buildComposite(field string, modelConfig ModelConfig) (tariff.Tariff, error) {
+	if modelConfig.Tariff == nil {
+		return nil, builder.fail(field+".tariff", fmt.Errorf("%w: %s model without a tariff", ErrInvalidValue, modelConfig.Model))
+	}
+	if modelConfig.Start != "" || modelConfig.End != "" {
+		return nil, builder.fail(field+".start", fmt.Errorf("%w: %s does not take a range", ErrInvalidValue, modelConfig.Model))
+	}
+	return builder.buildTariff(field+".tariff", *modelConfig.Tariff)
+}
+
+func (builder *builder) buildModel(field string, modelConfig ModelConfig) (tariff.ModelCalculator, error) {
+	if modelConfig.Price.IsNegative() {
+		return nil, builder.fail(field+".price", fmt.Errorf("%w: price %v must not be negative", ErrInvalidValue, modelConfig.Price))
+	}
+	if modelConfig.Model == tariff.TimeOfDayModel {
+		if modelConfig.Start != "" || modelConfig.End != "" {
+			return nil, builder.fail(field+".start", fmt.Errorf("%w: %s does not take a range", ErrInvalidValue, modelConfig.Model))
+		}
+		for i, bandConfig := range modelConfig.Bands {
+			if _, err := bandConfig.Build(); err != nil {
+				return nil, builder.fail(fmt.Sprintf("%s.bands[%d]", field, i), err)
+			}
+		}
+		model, err := modelConfig.Build()
+		if err != nil {
+			return nil, builder.fail(field+".bands", err)
+		}
+		return model, nil
+	}
+	if len(modelConfig.Bands) > 0 {
+		return nil, builder.fail(field+".bands", fmt.Errorf("%w: %s does not take bands", ErrInvalidValue, modelConfig.Model))
+	}
+	if modelConfig.Model == tariff.EveryHourModel && (modelConfig.Start != "" || modelConfig.End != "") {
+		return nil, builder.fail(field+".start", fmt.Errorf("%w: %s does not take a range", ErrInvalidValue, modelConfig.Model))
+	}
+	start, err := builder.minutes(field+".start", modelConfig.Start, 0)
+	if err != nil {
+		return nil, err
+	}
+	end, err := builder.minutes(field+".end", modelConfig.End, math.MaxFloat64)
+	if err != nil {
+		return nil, err
+	}
+	model, err := tariff.NewModel(modelConfig.Model, modelConfig.Price, tariff.NewTimeConstraint(start, end))
+	if errors.Is(err, tariff.ErrUnknownModel) {
+		return nil, builder.fail(field+".model", err)
+	}
+	if err != nil {
+		return nil, builder.fail(field, err)
+	}
+	if modelConfig.Granularity != nil {
+		granularity, err := modelConfig.Granularity.Build()
+		if err != nil {
+			return nil, builder.fail(field+".granularity", err)
+		}
+		if model, err = tariff.WithGranularity(model, granularity); err != nil {
+			return nil, builder.fail(field+".granularity", err)
+		}
+	}
+	return model, nil
+}
+
+func (builder *builder) minutes(field string, value string, missing float64) (float64, error) {
+	if value == "" {
+		return missing, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, builder.fail(field, fmt.Errorf("%w: %v", ErrInvalidValue, err))
+	}
+	return duration.Minutes(), nil
+}
@@ -0,0 +1,521 @@
+package config
+
+import (
+	"errors"
+	"github.com/hbkkanna/parking"
+	"github.com/hbkkanna/parking/charges"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"github.com/hbkkanna/parking/tariff"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadAirport(t *testing.T) {
+	configs, err := Load("testdata/airport.json")
+	if err != nil {
+		t.Fatalf("load failed %v", err)
+	}
+	clock := parking.NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := parking.NewParkingLot(configs, parking.WithClock(clock))
+
+	cases := []struct {
+		vehicleType int
+		stay        time.Duration
+		cost        money.Money
+	}{
+		{slot.SCOOTER, time.Minute * 59, money.New(0, "USD")},
+		{slot.SCOOTER, time.Minute * (14*60 + 59), money.New(6000, "USD")},
+		{slot.SCOOTER, time.Hour * (24 + 12), money.New(16000, "USD")},
+		{slot.SUV, time.Minute * 50, money.New(6000, "USD")},
+		{slot.SUV, time.Minute * (23*60 + 59), money.New(8000, "USD")},
+		{slot.SUV, time.Hour * (24*3 + 1), money.New(40000, "USD")},
+	}
+	for _, c := range cases {
+		clock.Set(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+		ticket, err := plot.Park(slot.NewRoadVehicle(c.vehicleType))
+		if err != nil {
+			t.Fatalf("park failed %v", err)
+		}
+		clock.Advance(c.stay)
+		receipt, err := plot.UnPark(ticket)
+		if err != nil || receipt.GetCost() != c.cost {
+			t.Errorf("stay %v expected %v, got %v %v", c.stay, c.cost, receipt, err)
+		}
+	}
+
+	// no truck slots at the airport
+	if _, err := plot.Park(slot.NewRoadVehicle(slot.TRUCK)); !errors.Is(err, parking.ErrNoSpace) {
+		t.Errorf("expected ErrNoSpace, got %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		data  string
+		line  int
+		field string
+		err   error
+	}{
+		{"unknown model", `{
+  "vehicles": [
+    {"type": "Suv", "slots": 10, "tariff": {"matcher": "Single", "models": [
+      {"model": "EveryHour", "price": "20 USD"},
+      {"model": "Monthly", "price": "20 USD"}
+    ]}}
+  ]
+}`, 5, "vehicles[0].tariff.models[1].model", tariff.ErrUnknownModel},
+		{"inverted range", `{
+  "vehicles": [
+    {
+      "type": "Suv",
+      "slots": 10,
+      "tariff": {
+        "matcher": "Single",
+        "models": [
+          {"model": "HourInterval", "price": "20 USD", "start": "8h", "end": "4h"}
+        ]
+      }
+    }
+  ]
+}`, 9, "vehicles[0].tariff.models[0]", tariff.ErrInvalidRange},
+		{"malformed duration", `{"vehicles": [
+  {"type": "Suv", "slots": 10, "tariff": {"matcher": "Single", "models": [
+    {"model": "HourInterval", "price": "20 USD",
+     "start": "four hours"}]}}]}`, 4, "vehicles[0].tariff.models[0].start", ErrInvalidValue},
+		{"unknown matcher", `{"vehicles": [
+  {"type": "Suv", "slots": 10, "tariff": {"matcher": "Priority", "models": []}}]}`, 2, "vehicles[0].tariff.matcher", tariff.ErrUnknownMatcher},
+		{"unknown vehicle", `{"vehicles": [
+  {"type": "Suv", "slots": 10, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "20 USD"}]}},
+  {"type": "Hovercraft", "slots": 10, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "20 USD"}]}}]}`,
+			3, "vehicles[1].type", ErrUnknownVehicle},
+		{"duplicate vehicle", `{"vehicles": [
+  {"type": "Suv", "slots": 10, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "20 USD"}]}},
+  {"type": "suv", "slots": 10, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "20 USD"}]}}]}`,
+			3, "vehicles[1].type", ErrDuplicateVehicle},
+		{"slot count", `{"vehicles": [
+  {"type": "Suv",
+   "slots": 0, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "20 USD"}]}}]}`,
+			3, "vehicles[0].slots", ErrInvalidValue},
+		{"malformed price", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [
+    {"model": "EveryHour", "price": "20 USD"},
+    {"model": "EveryHour", "price": 20}
+  ]}}
+]}`,
+			4, "vehicles[0].tariff.models[1].price", money.ErrInvalidAmount},
+		{"lot currency", `{"currency": "EUR", "vehicles": [
+  {"type": "Suv", "slots": 1,
+   "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "20 USD"}]}}]}`,
+			3, "vehicles[0].tariff", money.ErrCurrencyMismatch},
+		{"tariff currency", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "currency": "EUR", "models": [
+    {"model": "EveryHour", "price": "20 USD"}]}}]}`,
+			3, "vehicles[0].tariff.models[0].price", money.ErrCurrencyMismatch},
+		{"mixed model currency", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Multiple", "models": [
+    {"model": "EveryHour", "price": "20 USD"},
+    {"model": "EveryHour", "price": "20 EUR"}]}}]}`,
+			4, "vehicles[0].tariff.models[1]", ErrInvalidValue},
+		{"every hour range", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [
+    {"model": "EveryHour", "price": "20 USD", "start": "1h"}]}}]}`,
+			3, "vehicles[0].tariff.models[0].start", ErrInvalidValue},
+		{"gap", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single",
+    "models": [{"model": "HourInterval", "price": "20 USD", "end": "4h"}]}}]}`,
+			3, "vehicles[0].tariff.models", ErrInvalidValue},
+		{"unreachable", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [
+    {"model": "EveryHour", "price": "20 USD"},
+    {"model": "HourInterval", "price": "20 USD", "end": "4h"}]}}]}`,
+			4, "vehicles[0].tariff.models[1]", ErrInvalidValue},
+		{"unknown charge", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "20 USD"}]}}],
+ "charges": [
+  {"type": "Tip", "amount": "1 USD"}]}`,
+			4, "charges[0].type", ErrInvalidValue},
+		{"tax percent", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "20 USD"}]}}],
+ "charges": [{"type": "Tax", "name": "VAT",
+   "percent": "twenty"}]}`,
+			4, "charges[0].percent", charges.ErrInvalidRate},
+		{"charge currency", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "20 USD"}]}}],
+ "charges": [
+  {"type": "Surcharge", "name": "Facility fee", "amount": "2 EUR"}]}`,
+			4, "charges[0].amount", money.ErrCurrencyMismatch},
+		{"cap period", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single",
+    "caps": {"daily": "30 USD", "period": "Weekly"}, "models": [{"model": "EveryHour", "price": "3 USD"}]}}]}`,
+			3, "vehicles[0].tariff.caps", tariff.ErrInvalidCap},
+		{"grace tolerance", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single",
+    "grace": {"tolerance": "90m"}, "models": [{"model": "EveryHour", "price": "3 USD"}]}}]}`,
+			3, "vehicles[0].tariff.grace", tariff.ErrInvalidGrace},
+		{"flat granularity", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [
+    {"model": "HourInterval", "price": "3 USD", "end": "2h",
+     "granularity": {"increment": "15m"}},
+    {"model": "EveryHour", "price": "3 USD"}]}}]}`,
+			4, "vehicles[0].tariff.models[0].granularity", tariff.ErrInvalidGranularity},
+		{"event without calendar", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "20 USD"}]},
+   "event": {"surcharge": "5 USD"}}]}`,
+			3, "vehicles[0].event", ErrInvalidValue},
+		{"band clock", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [
+    {"model": "TimeOfDay", "price": "1 USD", "bands": [
+      {"name": "Day", "from": "08:00", "to": "18:00", "price": "5 USD"},
+      {"name": "Night", "from": "18:00", "to": "8", "price": "3 USD"}]}]}}]}`,
+			5, "vehicles[0].tariff.models[0].bands[1]", tariff.ErrInvalidBand},
+	}
+	for _, c := range cases {
+		_, err := Parse([]byte(c.data))
+		var configError *Error
+		if !errors.As(err, &configError) {
+			t.Errorf("%s: expected config error, got %v", c.name, err)
+			continue
+		}
+		if configError.Line != c.line || configError.Field != c.field || !errors.Is(err, c.err) {
+			t.Errorf("%s: expected line %d field %s, got %v", c.name, c.line, c.field, err)
+		}
+	}
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	configs, err := Parse([]byte(`{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [
+    {"model": "TimeOfDay", "price": "1 USD", "bands": [
+      {"name": "Weekend", "from": "00:00", "to": "24:00", "days": ["Weekend"], "price": "2 USD"},
+      {"name": "Day", "from": "08:00", "to": "18:00", "days": ["Weekdays"], "price": "5 USD"},
+      {"name": "Night", "from": "18:00", "to": "08:00", "price": "3 USD"}]}]}}]}`))
+	if err != nil {
+		t.Fatalf("parse failed %v", err)
+	}
+	// Wednesday 16:00 to 20:00, 2h day and 2h night
+	clock := parking.NewFakeClock(time.Date(2021, 1, 6, 16, 0, 0, 0, time.UTC))
+	plot := parking.NewParkingLot(configs, parking.WithClock(clock))
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SUV))
+	clock.Advance(time.Hour * 4)
+	receipt, err := plot.UnPark(ticket)
+	if err != nil || receipt.GetCost() != money.New(1600, "USD") {
+		t.Errorf("unexpected time of day receipt %v %v", receipt, err)
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	configs, err := Parse([]byte(`{
+  "calendar": {"events": [{"name": "Cup Final", "start": "2021-05-15T12:00:00Z", "end": "2021-05-15T20:00:00Z"}]},
+  "vehicles": [
+    {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "10 USD"}]},
+     "event": {"tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "25 USD"}]}, "surcharge": "5 USD"}}]}`))
+	if err != nil {
+		t.Fatalf("parse failed %v", err)
+	}
+	clock := parking.NewFakeClock(time.Date(2021, 5, 15, 18, 0, 0, 0, time.UTC))
+	plot := parking.NewParkingLot(configs, parking.WithClock(clock))
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SUV))
+	clock.Advance(time.Hour * 2)
+	receipt, err := plot.UnPark(ticket)
+	if err != nil || receipt.GetCost() != money.New(5500, "USD") {
+		t.Fatalf("unexpected event receipt %v %v", receipt, err)
+	}
+	if lines := receipt.GetLines(); len(lines) != 2 || lines[1].Description != "EventSurcharge (Cup Final)" {
+		t.Errorf("receipt must name the event %v", lines)
+	}
+}
+
+func TestParseComposite(t *testing.T) {
+	// cheapest of 3 USD per hour and the day rate, plus a flat fee of 2 USD
+	configs, err := Parse([]byte(`{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Multiple", "models": [
+    {"model": "Composite", "tariff": {"matcher": "Cheapest", "models": [
+      {"model": "EveryHour", "price": "3 USD"},
+      {"model": "EveryDay", "price": "20 USD"}]}},
+    {"model": "HourInterval", "price": "2 USD"}]}}]}`))
+	if err != nil {
+		t.Fatalf("parse failed %v", err)
+	}
+	clock := parking.NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := parking.NewParkingLot(configs, parking.WithClock(clock))
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SUV))
+	clock.Advance(time.Hour * 10)
+	receipt, err := plot.UnPark(ticket)
+	if err != nil || receipt.GetCost() != money.New(2200, "USD") {
+		t.Fatalf("unexpected composite receipt %v %v", receipt, err)
+	}
+
+	_, err = Parse([]byte(`{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Max", "models": [
+    {"model": "Composite", "tariff": {"matcher": "Single", "models": [
+      {"model": "HourInterval", "price": "2 USD", "end": "1h"}]}}]}}]}`))
+	var configError *Error
+	if !errors.As(err, &configError) || configError.Line != 3 || configError.Field != "vehicles[0].tariff.models[0].tariff.models" {
+		t.Errorf("expected gap in the composite model, got %v", err)
+	}
+}
+
+func TestParseLostTicket(t *testing.T) {
+	configs, err := Parse([]byte(`{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "3 USD"}]},
+   "lostTicket": {"matcher": "Single", "models": [{"model": "HourInterval", "price": "40 USD"}]}}]}`))
+	if err != nil || configs[0].GetLostTicketTariff() == nil {
+		t.Fatalf("parse failed %v", err)
+	}
+	_, err = Parse([]byte(`{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "3 USD"}]},
+   "lostTicket": {"matcher": "Single", "models": [{"model": "HourInterval", "price": "40 EUR"}]}}]}`))
+	var configError *Error
+	if !errors.As(err, &configError) || configError.Field != "vehicles[0].lostTicket" || !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("expected currency mismatch, got %v", err)
+	}
+}
+
+func TestParseVehicleTypes(t *testing.T) {
+	data := []byte(`{
+  "vehicleTypes": [{"name": "Van", "size": "Medium", "metadata": {"icon": "van"}}],
+  "vehicles": [
+    {"type": "Van", "slots": 2, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "4 USD"}]}}]}`)
+	// loading the same config again reuses the registered type
+	for i := 0; i < 2; i++ {
+		if _, err := Parse(data); err != nil {
+			t.Fatalf("parse failed %v", err)
+		}
+	}
+	van, err := slot.LookupVehicleType("Van")
+	if err != nil || van.Size != slot.SizeMedium {
+		t.Fatalf("vehicle type not registered %v", err)
+	}
+	t.Cleanup(func() { slot.UnregisterVehicleType(van.ID) })
+
+	cases := []struct {
+		name  string
+		data  string
+		field string
+		err   error
+	}{
+		{"size", `{"vehicleTypes": [{"name": "Bus", "size": "Huge"}], "vehicles": []}`, "vehicleTypes[0].size", slot.ErrInvalidSizeClass},
+		{"redefined", `{"vehicleTypes": [{"name": "Van", "size": "Large"}], "vehicles": []}`, "vehicleTypes[0].name", slot.ErrDuplicateVehicleType},
+		{"unknown", `{"vehicles": [{"type": "Tram", "slots": 1, "tariff": {"matcher": "Single", "models": []}}]}`, "vehicles[0].type", slot.ErrUnknownVehicleType},
+	}
+	for _, c := range cases {
+		_, err := Parse([]byte(c.data))
+		var configError *Error
+		if !errors.As(err, &configError) || configError.Field != c.field || !errors.Is(err, c.err) {
+			t.Errorf("%s: expected field %s, got %v", c.name, c.field, err)
+		}
+	}
+}
+
+func TestRejectedVehicleTypes(t *testing.T) {
+	rejected := []byte(`{
+  "vehicleTypes": [{"name": "Rickshaw", "size": "Small"}],
+  "vehicles": [
+    {"type": "Rickshaw", "slots": 0, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "4 USD"}]}}]}`)
+	if _, err := Parse(rejected); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("expected ErrInvalidValue, got %v", err)
+	}
+	if _, err := slot.LookupVehicleType("Rickshaw"); !errors.Is(err, slot.ErrUnknownVehicleType) {
+		t.Errorf("rejected config must not register its types, got %v", err)
+	}
+
+	// the corrected config may define the type differently
+	corrected := []byte(`{
+  "vehicleTypes": [{"name": "Rickshaw", "size": "Medium"}],
+  "vehicles": [
+    {"type": "Rickshaw", "slots": 2, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "4 USD"}]}}]}`)
+	if _, err := Parse(corrected); err != nil {
+		t.Fatalf("corrected config failed %v", err)
+	}
+	rickshaw, err := slot.LookupVehicleType("Rickshaw")
+	if err != nil || rickshaw.Size != slot.SizeMedium {
+		t.Fatalf("vehicle type not registered %v", err)
+	}
+	slot.UnregisterVehicleType(rickshaw.ID)
+}
+
+func TestConcurrentVehicleTypes(t *testing.T) {
+	// a rejected config between registering its types and rolling them back
+	buildMutex.Lock()
+	rejected := &builder{positions: newPositions(nil)}
+	if err := rejected.registerVehicleTypes([]VehicleTypeConfig{{Name: "Tuktuk", Size: "Small"}}); err != nil {
+		buildMutex.Unlock()
+		t.Fatalf("register failed %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := Parse([]byte(`{
+  "vehicleTypes": [{"name": "Tuktuk", "size": "Small"}],
+  "vehicles": [
+    {"type": "Tuktuk", "slots": 2, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "4 USD"}]}}]}`))
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	rejected.unregisterVehicleTypes()
+	buildMutex.Unlock()
+	if err := <-done; err != nil {
+		t.Fatalf("accepted config failed %v", err)
+	}
+
+	// the rollback must not take the type of the accepted config with it
+	tuktuk, err := slot.LookupVehicleType("Tuktuk")
+	if err != nil {
+		t.Fatalf("vehicle type of the accepted config removed %v", err)
+	}
+	slot.UnregisterVehicleType(tuktuk.ID)
+}
+
+func TestParseOverflow(t *testing.T) {
+	configs, err := Parse([]byte(`{"vehicles": [
+  {"type": "Scooter", "slots": 1, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "1 USD"}]},
+   "overflow": [{"slots": "Suv", "reserve": 2}]},
+  {"type": "Suv", "slots": 5, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "3 USD"}]}}]}`))
+	if err != nil {
+		t.Fatalf("parse failed %v", err)
+	}
+	if overflow := configs[0].GetOverflow(); len(overflow) != 1 || overflow[0].SlotType != slot.SUV || overflow[0].Reserve != 2 {
+		t.Errorf("unexpected overflow %v", overflow)
+	}
+
+	cases := []struct {
+		name  string
+		data  string
+		field string
+		err   error
+	}{
+		{"smaller", `{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "1 USD"}]},
+   "overflow": [{"slots": "Scooter"}]},
+  {"type": "Scooter", "slots": 1, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "1 USD"}]}}]}`,
+			"vehicles[0].overflow[0]", parking.ErrIncompatibleSize},
+		{"missing", `{"vehicles": [
+  {"type": "Scooter", "slots": 1, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "1 USD"}]},
+   "overflow": [{"slots": "Truck"}]}]}`, "vehicles[0].overflow[0].slots", ErrInvalidValue},
+	}
+	for _, c := range cases {
+		_, err := Parse([]byte(c.data))
+		var configError *Error
+		if !errors.As(err, &configError) || configError.Field != c.field || !errors.Is(err, c.err) {
+			t.Errorf("%s: expected field %s, got %v", c.name, c.field, err)
+		}
+	}
+}
+
+func TestParseCaps(t *testing.T) {
+	configs, err := Parse([]byte(`{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single",
+    "caps": {"daily": "30 USD"}, "models": [{"model": "EveryHour", "price": "3 USD"}]}}]}`))
+	if err != nil {
+		t.Fatalf("parse failed %v", err)
+	}
+	clock := parking.NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := parking.NewParkingLot(configs, parking.WithClock(clock))
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SUV))
+	clock.Advance(time.Hour * 30)
+	receipt, err := plot.UnPark(ticket)
+	if err != nil || receipt.GetCost() != money.New(4800, "USD") {
+		t.Fatalf("unexpected capped receipt %v %v", receipt, err)
+	}
+	if lines := receipt.GetLines(); len(lines) != 2 || lines[1].Description != "DailyCap [0s, 24h0m0s)" {
+		t.Errorf("receipt must state the cap %v", lines)
+	}
+}
+
+func TestParseGrace(t *testing.T) {
+	configs, err := Parse([]byte(`{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single",
+    "grace": {"free": "10m", "tolerance": "5m"}, "models": [{"model": "EveryHour", "price": "3 USD"}]}}]}`))
+	if err != nil {
+		t.Fatalf("parse failed %v", err)
+	}
+	clock := parking.NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := parking.NewParkingLot(configs, parking.WithClock(clock))
+	for _, c := range []struct {
+		stay time.Duration
+		cost money.Money
+	}{{time.Minute * 9, money.New(0, "USD")}, {time.Minute * 182, money.New(900, "USD")}} {
+		ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SUV))
+		clock.Advance(c.stay)
+		receipt, err := plot.UnPark(ticket)
+		if err != nil || receipt.GetCost() != c.cost {
+			t.Errorf("stay %v expected %v, got %v %v", c.stay, c.cost, receipt, err)
+		}
+	}
+}
+
+func TestParseGranularity(t *testing.T) {
+	configs, err := Parse([]byte(`{"vehicles": [
+  {"type": "Scooter", "slots": 1, "tariff": {"matcher": "Single", "models": [
+    {"model": "EveryHour", "price": "4 USD", "granularity": {"increment": "15m"}}]}},
+  {"type": "Truck", "slots": 1, "tariff": {"matcher": "Single", "models": [
+    {"model": "EveryHour", "price": "20 USD"}]}}]}`))
+	if err != nil {
+		t.Fatalf("parse failed %v", err)
+	}
+	clock := parking.NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := parking.NewParkingLot(configs, parking.WithClock(clock))
+	scooter, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	truck, _ := plot.Park(slot.NewRoadVehicle(slot.TRUCK))
+	clock.Advance(time.Minute * 70)
+	if receipt, err := plot.UnPark(scooter); err != nil || receipt.GetCost() != money.New(500, "USD") {
+		t.Errorf("scooter must be billed per 15 minutes %v %v", receipt, err)
+	}
+	if receipt, err := plot.UnPark(truck); err != nil || receipt.GetCost() != money.New(4000, "USD") {
+		t.Errorf("truck must be billed per hour %v %v", receipt, err)
+	}
+}
+
+func TestParseCharges(t *testing.T) {
+	configs, err := Parse([]byte(`{"vehicles": [
+  {"type": "Suv", "slots": 1, "tariff": {"matcher": "Single", "models": [{"model": "EveryHour", "price": "20 CHF"}]}}],
+ "charges": [
+  {"type": "Surcharge", "name": "Facility fee", "amount": "2 CHF"},
+  {"type": "Tax", "name": "VAT", "percent": "8.1"},
+  {"type": "Rounding", "increment": "0.05 CHF"}]}`))
+	if err != nil {
+		t.Fatalf("parse failed %v", err)
+	}
+	// 20.00 + 2.00 = 22.00 net, 1.782 tax rounds to 1.78, 23.78 gross rounds to 23.80
+	bill := configs[0].GetCharges().Apply(money.New(2000, "CHF"))
+	if bill.Net != money.New(2200, "CHF") || bill.Tax != money.New(178, "CHF") || bill.Gross() != money.New(2380, "CHF") {
+		t.Errorf("unexpected bill %+v", bill)
+	}
+}
+
+func TestParseDecodeErrors(t *testing.T) {
+	_, err := Parse([]byte("{\n  \"vehicles\": [\n    {\"type\": \"Suv\", \"slots\": \"ten\"}\n  ]\n}"))
+	var configError *Error
+	if !errors.As(err, &configError) || configError.Line != 3 || !strings.HasSuffix(configError.Field, ".slots") {
+		t.Errorf("expected type error on line 3, got %v", err)
+	}
+
+	_, err = Parse([]byte("{\n  \"vehicles\": [\n    {\"type\": \"Suv\", \"slot\": 10}\n  ]\n}"))
+	if !errors.As(err, &configError) || configError.Line != 3 || configError.Field != "vehicles[0].slot" {
+		t.Errorf("expected unknown field on line 3, got %v", err)
+	}
+
+	// price is a valid field of the models, only the vehicle does not declare it
+	data := []byte(`{
+  "vehicles": [
+    {"type": "Suv", "slots": 10,
+     "tariff": {"matcher": "Single", "models": [
+       {"model": "EveryHour", "price": "3 USD"}]},
+     "price": "5 USD"}
+  ]
+}`)
+	for i := 0; i < 20; i++ {
+		_, err = Parse(data)
+		if !errors.As(err, &configError) || configError.Line != 6 || configError.Field != "vehicles[0].price" {
+			t.Fatalf("expected unknown field on line 6, got %v", err)
+		}
+	}
+
+	_, err = Parse([]byte("{\n  \"vehicles\": [\n    {\"type\": \"Suv\",, }\n  ]\n}"))
+	if !errors.As(err, &configError) || configError.Line != 3 {
+		t.Errorf("expected syntax error on line 3, got %v", err)
+	}
+}
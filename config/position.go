@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// positions maps the path of every value in a JSON document, e.g. vehicles[0].tariff.models[1].price,
+// to the byte offset the value starts at
+type positions struct {
+	data    []byte
+	offsets map[string]int64
+}
+
+func newPositions(data []byte) *positions {
+	positions := &positions{data: data, offsets: make(map[string]int64)}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// a malformed document is reported by the decoder, offsets up to the error are kept
+	positions.walk(decoder, "")
+	return positions
+}
+
+func (positions *positions) walk(decoder *json.Decoder, path string) error {
+	positions.offsets[path] = positions.skipSeparators(decoder.InputOffset())
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			name, _ := key.(string)
+			if path != "" {
+				name = path + "." + name
+			}
+			if err := positions.walk(decoder, name); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+	case json.Delim('['):
+		for i := 0; decoder.More(); i++ {
+			if err := positions.walk(decoder, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+	}
+	return err
+}
+
+func (positions *positions) skipSeparators(offset int64) int64 {
+	for offset < int64(len(positions.data)) {
+		switch positions.data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// line returns the line of the path, falling back to its closest parent
+func (positions *positions) line(path string) int {
+	for {
+		if offset, ok := positions.offsets[path]; ok {
+			return positions.offsetLine(offset)
+		}
+		cut := bytes.LastIndexAny([]byte(path), ".[")
+		if cut < 0 {
+			return positions.offsetLine(positions.offsets[""])
+		}
+		path = path[:cut]
+	}
+}
+
+func (positions *positions) offsetLine(offset int64) int {
+	if offset > int64(len(positions.data)) {
+		offset = int64(len(positions.data))
+	}
+	return bytes.Count(positions.data[:offset], []byte("\n")) + 1
+}
+
+// raw returns the JSON value at the path
+func (positions *positions) raw(path string) []byte {
+	offset, ok := positions.offsets[path]
+	if !ok {
+		return nil
+	}
+	var value json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(positions.data[offset:])).Decode(&value); err != nil {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,71 @@
+package parking
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hbkkanna/parking/slot"
+	"github.com/hbkkanna/parking/tariff"
+)
+
+var (
+	ErrNoSpace        = errors.New("no space available")
+	ErrSlotNotFound   = errors.New("slot not found")
+	ErrUnknownVehicle = slot.ErrUnknownVehicleType
+	ErrNotInRange     = tariff.ErrNotInRange
+)
+
+// NoSpaceError : all slots of the vehicle type are taken, matches ErrNoSpace
+type NoSpaceError struct {
+	VehicleType int
+	Capacity    int
+}
+
+func (noSpaceError *NoSpaceError) Error() string {
+	return fmt.Sprintf("no space available for vehicle type %d, capacity %d", noSpaceError.VehicleType, noSpaceError.Capacity)
+}
+
+func (noSpaceError *NoSpaceError) Is(target error) bool {
+	return target == ErrNoSpace
+}
+
+// SlotNotFoundError : the lot has no slot with the number for the vehicle type, matches ErrSlotNotFound
+type SlotNotFoundError struct {
+	VehicleType int
+	Number      int
+}
+
+func (slotNotFoundError *SlotNotFoundError) Error() string {
+	return fmt.Sprintf("slot not found for vehicle type %d, for number %d", slotNotFoundError.VehicleType, slotNotFoundError.Number)
+}
+
+func (slotNotFoundError *SlotNotFoundError) Is(target error) bool {
+	return target == ErrSlotNotFound
+}
+
+// TicketError : rejected ticket, unwraps to ErrTicketUnknown, ErrTicketAlreadyUsed, ErrTicketVoided or ErrSlotMismatch
+type TicketError struct {
+	TicketNumber int
+	Err          error
+}
+
+func (ticketError *TicketError) Error() string {
+	return fmt.Sprintf("ticket %d: %v", ticketError.TicketNumber, ticketError.Err)
+}
+
+func (ticketError *TicketError) Unwrap() error {
+	return ticketError.Err
+}
+
+// PlateParkedError : the plate is already parked under an issued ticket, matches ErrPlateParked
+type PlateParkedError struct {
+	Plate        string
+	TicketNumber int
+}
+
+func (plateParkedError *PlateParkedError) Error() string {
+	return fmt.Sprintf("plate %s already parked with ticket %d", plateParkedError.Plate, plateParkedError.TicketNumber)
+}
+
+func (plateParkedError *PlateParkedError) Is(target error) bool {
+	return target == ErrPlateParked
+}
@@ -0,0 +1,47 @@
+package parking
+
+import (
+	"errors"
+	"github.com/hbkkanna/parking/slot"
+	"testing"
+)
+
+func TestNoSpaceError(t *testing.T) {
+	plot := NewParkingLot(SmallParkingLotConfig())
+	plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+
+	_, err := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	if !errors.Is(err, ErrNoSpace) {
+		t.Errorf("expected ErrNoSpace, got %v", err)
+	}
+	var noSpace *NoSpaceError
+	if !errors.As(err, &noSpace) || noSpace.VehicleType != slot.SCOOTER || noSpace.Capacity != 2 {
+		t.Errorf("expected NoSpaceError for scooter with capacity 2, got %v", err)
+	}
+
+	// vehicle type without slots
+	_, err = plot.Park(slot.NewRoadVehicle(slot.TRUCK))
+	if !errors.As(err, &noSpace) || noSpace.VehicleType != slot.TRUCK || noSpace.Capacity != 0 {
+		t.Errorf("expected NoSpaceError for truck with capacity 0, got %v", err)
+	}
+}
+
+func TestSlotNotFoundError(t *testing.T) {
+	plot := NewParkingLot(SmallParkingLotConfig())
+	lot, _ := plot.(*VehicleParkingLot)
+	_, err := lot.getSlot(slot.SUV, 1)
+	var notFound *SlotNotFoundError
+	if !errors.Is(err, ErrSlotNotFound) || !errors.As(err, &notFound) || notFound.VehicleType != slot.SUV || notFound.Number != 1 {
+		t.Errorf("expected SlotNotFoundError, got %v", err)
+	}
+}
+
+func TestTicketError(t *testing.T) {
+	plot := NewParkingLot(SmallParkingLotConfig())
+	_, err := plot.UnPark(slot.NewTicket(42, slot.NewVehicleSlot(slot.Vehicles[slot.SCOOTER], 0)))
+	var ticketError *TicketError
+	if !errors.As(err, &ticketError) || ticketError.TicketNumber != 42 || !errors.Is(err, ErrTicketUnknown) {
+		t.Errorf("expected TicketError for ticket 42, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package parking
+
+import (
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"log"
+)
+
+// Event is published by the parking lot for every gate operation
+type Event interface {
+	EventName() string
+}
+
+// VehicleParked : a ticket was issued
+type VehicleParked struct {
+	TicketNumber int
+	VehicleType  int
+	SlotNumber   int
+	Ticket       slot.Ticket
+}
+
+func (vehicleParked VehicleParked) EventName() string {
+	return "VehicleParked"
+}
+
+// VehicleUnparked : a ticket was redeemed and the receipt issued, LostTicket is set for
+// exits without the ticket
+type VehicleUnparked struct {
+	TicketNumber  int
+	ReceiptNumber int
+	VehicleType   int
+	SlotNumber    int
+	Cost          money.Money
+	Receipt       slot.Receipt
+	LostTicket    bool
+}
+
+func (vehicleUnparked VehicleUnparked) EventName() string {
+	return "VehicleUnparked"
+}
+
+// ParkRejected : no ticket was issued for the vehicle
+type ParkRejected struct {
+	VehicleType int
+	Err         error
+}
+
+func (parkRejected ParkRejected) EventName() string {
+	return "ParkRejected"
+}
+
+// EventSink receives the events of the parking lot, it is called while the vehicle type is locked
+// and must not call back into the lot.
+type EventSink interface {
+	Publish(event Event)
+}
+
+// EventSinkFunc adapts a function to EventSink
+type EventSinkFunc func(event Event)
+
+func (eventSinkFunc EventSinkFunc) Publish(event Event) {
+	eventSinkFunc(event)
+}
+
+// NopEventSink discards every event, default sink of the parking lot
+type NopEventSink struct{}
+
+func (nopEventSink NopEventSink) Publish(event Event) {}
+
+// LogEventSink writes one line per event to the logger
+type LogEventSink struct {
+	logger *log.Logger
+}
+
+func (logEventSink *LogEventSink) Publish(event Event) {
+	logEventSink.logger.Printf("%s %+v", event.EventName(), event)
+}
+
+func NewLogEventSink(logger *log.Logger) EventSink {
+	return &LogEventSink{logger: logger}
+}
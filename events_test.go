@@ -0,0 +1,49 @@
+package parking
+
+import (
+	"errors"
+	"github.com/hbkkanna/parking/slot"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventSink(t *testing.T) {
+	var events []Event
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot(SmallParkingLotConfig(), WithClock(clock), WithEventSink(EventSinkFunc(func(event Event) {
+		events = append(events, event)
+	})))
+
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	clock.Advance(time.Hour * 2)
+	plot.UnPark(ticket)
+
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(events))
+	}
+	parked, ok := events[0].(VehicleParked)
+	if !ok || parked.TicketNumber != ticket.GetTicketNumber() || parked.SlotNumber != ticket.GetNumber() || parked.VehicleType != slot.SCOOTER {
+		t.Errorf("unexpected VehicleParked event %v", events[0])
+	}
+	rejected, ok := events[2].(ParkRejected)
+	if !ok || !errors.Is(rejected.Err, ErrNoSpace) || rejected.EventName() != "ParkRejected" {
+		t.Errorf("unexpected ParkRejected event %v", events[2])
+	}
+	unparked, ok := events[3].(VehicleUnparked)
+	if !ok || unparked.TicketNumber != ticket.GetTicketNumber() || unparked.Cost != usd(20) || unparked.Receipt == nil {
+		t.Errorf("unexpected VehicleUnparked event %v", events[3])
+	}
+}
+
+func TestLogEventSink(t *testing.T) {
+	var out strings.Builder
+	plot := NewParkingLot(SmallParkingLotConfig(), WithEventSink(NewLogEventSink(log.New(&out, "", 0))))
+	plot.Park(slot.NewRoadVehicle(slot.TRUCK))
+	if !strings.HasPrefix(out.String(), "ParkRejected ") {
+		t.Errorf("unexpected log output %q", out.String())
+	}
+}
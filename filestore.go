@@ -0,0 +1,166 @@
+package parking
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	journalFile  = "journal.log"
+	snapshotFile = "snapshot.json"
+)
+
+// FileStore : append only journal of JSON lines plus the latest snapshot in a directory.
+// Every append is synced to disk, a torn record at the end of the journal is dropped on load.
+type FileStore struct {
+	mutex   sync.Mutex
+	dir     string
+	journal *os.File
+}
+
+func (fileStore *FileStore) Append(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	fileStore.mutex.Lock()
+	defer fileStore.mutex.Unlock()
+	if _, err := fileStore.journal.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return fileStore.journal.Sync()
+}
+
+// Save replaces the snapshot and then truncates the journal, a crash in between leaves
+// journal records the snapshot already covers and they are skipped by sequence on recovery.
+func (fileStore *FileStore) Save(snapshot Snapshot) error {
+	fileStore.mutex.Lock()
+	defer fileStore.mutex.Unlock()
+	if err := fileStore.writeSnapshot(snapshot); err != nil {
+		return err
+	}
+	if err := fileStore.journal.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := fileStore.journal.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return fileStore.journal.Sync()
+}
+
+func (fileStore *FileStore) writeSnapshot(snapshot Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(fileStore.dir, snapshotFile+".tmp")
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(fileStore.dir, snapshotFile))
+}
+
+func (fileStore *FileStore) Load() (*Snapshot, []Record, error) {
+	fileStore.mutex.Lock()
+	defer fileStore.mutex.Unlock()
+	snapshot, err := fileStore.readSnapshot()
+	if err != nil {
+		return nil, nil, err
+	}
+	records, err := fileStore.readJournal()
+	if err != nil {
+		return nil, nil, err
+	}
+	return snapshot, records, nil
+}
+
+func (fileStore *FileStore) readSnapshot() (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(fileStore.dir, snapshotFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", snapshotFile, err)
+	}
+	return snapshot, nil
+}
+
+// readJournal reads all complete records and cuts off a torn last record
+func (fileStore *FileStore) readJournal() ([]Record, error) {
+	if _, err := fileStore.journal.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var records []Record
+	var offset int64
+	reader := bufio.NewReader(fileStore.journal)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// incomplete last record, the write never finished
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var record Record
+		if err := json.Unmarshal(bytes.TrimSpace(data), &record); err != nil {
+			if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("journal %s line %d: %w", journalFile, line, err)
+		}
+		records = append(records, record)
+		offset += int64(len(data))
+	}
+	if err := fileStore.journal.Truncate(offset); err != nil {
+		return nil, err
+	}
+	if _, err := fileStore.journal.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (fileStore *FileStore) Close() error {
+	fileStore.mutex.Lock()
+	defer fileStore.mutex.Unlock()
+	return fileStore.journal.Close()
+}
+
+// NewFileStore opens or creates the store in the directory
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	journal, err := os.OpenFile(filepath.Join(dir, journalFile), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := journal.Seek(0, io.SeekEnd); err != nil {
+		journal.Close()
+		return nil, err
+	}
+	return &FileStore{dir: dir, journal: journal}, nil
+}
@@ -0,0 +1,76 @@
+package parking
+
+import (
+	"github.com/hbkkanna/parking/slot"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStoreTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := openLot(t, dir, clock)
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+
+	// crash while the next record was being written
+	journal, _ := os.OpenFile(filepath.Join(dir, journalFile), os.O_WRONLY|os.O_APPEND, 0644)
+	journal.WriteString(`{"seq":2,"op":"park","tick`)
+	journal.Close()
+
+	recovered := openLot(t, dir, clock)
+	next, err := recovered.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	if err != nil || next.GetTicketNumber() != 2 {
+		t.Errorf("torn record not dropped %v", err)
+	}
+	clock.Advance(time.Hour)
+	if _, err := recovered.UnPark(ticket); err != nil {
+		t.Errorf("unpark after torn record failed %v", err)
+	}
+
+	// torn record is gone from the journal as well
+	store, _ := NewFileStore(dir)
+	defer store.Close()
+	_, records, err := store.Load()
+	if err != nil || len(records) != 3 {
+		t.Errorf("expected 3 journal records, got %d %v", len(records), err)
+	}
+}
+
+func TestFileStoreCrashDuringSave(t *testing.T) {
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	store, _ := NewFileStore(dir)
+	defer store.Close()
+	plot, _ := OpenParkingLot(MallParkingLotConfig(), store, WithClock(clock))
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	clock.Advance(time.Hour)
+	plot.UnPark(ticket)
+
+	// snapshot written but the journal was not truncated before the crash
+	lot := plot.(*VehicleParkingLot)
+	lot.store = &snapshotOnlyStore{FileStore: store}
+	if err := lot.Checkpoint(); err != nil {
+		t.Fatalf("checkpoint failed %v", err)
+	}
+
+	recovered := openLot(t, dir, clock)
+	next, _ := recovered.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	if next.GetTicketNumber() != 3 || next.GetNumber() != 0 {
+		t.Errorf("journal records covered by snapshot were replayed twice")
+	}
+	recoveredLot := recovered.(*VehicleParkingLot)
+	if recoveredLot.receiptCnt != 1 || recoveredLot.slots[slot.SCOOTER][1].IsFree() {
+		t.Errorf("state not recovered from snapshot")
+	}
+}
+
+type snapshotOnlyStore struct {
+	*FileStore
+}
+
+func (store *snapshotOnlyStore) Save(snapshot Snapshot) error {
+	return store.writeSnapshot(snapshot)
+}
@@ -0,0 +1,59 @@
+package parking
+
+import (
+	"fmt"
+	"github.com/hbkkanna/parking/slot"
+)
+
+// LostTicket : finds the occupancy of a lost ticket, by plate when the plate is set,
+// otherwise by vehicle type and slot number
+type LostTicket struct {
+	Plate       string
+	VehicleType int
+	SlotNumber  int
+}
+
+// LostTicketExit lets a vehicle out without its ticket. The stay is billed from the recorded
+// in-time with the lost ticket tariff of the vehicle type, or its tariff when it has none,
+// and the original ticket is voided.
+func (parkingLot *VehicleParkingLot) LostTicketExit(lost LostTicket) (slot.Receipt, error) {
+	vehicleType := lost.VehicleType
+	if lost.Plate != "" {
+		record, ok := parkingLot.tickets.find(lost.Plate)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrPlateNotFound, lost.Plate)
+		}
+		vehicleType = record.vehicleType
+	}
+	unlock := parkingLot.lock(vehicleType)
+	defer unlock()
+	record, err := parkingLot.findOccupancy(lost, vehicleType)
+	if err != nil {
+		return nil, err
+	}
+	vehicleSlot, err := parkingLot.getSlot(record.vehicleType, record.slotNumber)
+	if err != nil {
+		return nil, err
+	}
+	return parkingLot.checkout(record.ticketNumber, vehicleSlot, OpLostTicket)
+}
+
+// findOccupancy returns the issued ticket of the lost ticket, the vehicle type must be locked
+func (parkingLot *VehicleParkingLot) findOccupancy(lost LostTicket, vehicleType int) (ticketRecord, error) {
+	if lost.Plate != "" {
+		// the vehicle may have left since the lookup
+		record, ok := parkingLot.tickets.find(lost.Plate)
+		if !ok || record.vehicleType != vehicleType {
+			return ticketRecord{}, fmt.Errorf("%w: %s", ErrPlateNotFound, lost.Plate)
+		}
+		return record, nil
+	}
+	if _, err := parkingLot.getSlot(vehicleType, lost.SlotNumber); err != nil {
+		return ticketRecord{}, err
+	}
+	record, ok := parkingLot.tickets.occupant(vehicleType, lost.SlotNumber)
+	if !ok {
+		return ticketRecord{}, fmt.Errorf("%w: vehicle type %d, slot %d", ErrSlotFree, vehicleType, lost.SlotNumber)
+	}
+	return record, nil
+}
@@ -0,0 +1,119 @@
+package parking
+
+import (
+	"errors"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"github.com/hbkkanna/parking/tariff"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLostTicketExit(t *testing.T) {
+	var events []Event
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot(SmallParkingLotConfig(), WithClock(clock), WithEventSink(EventSinkFunc(func(event Event) {
+		events = append(events, event)
+	})))
+	byPlate, _ := plot.Park(slot.NewRegisteredVehicle(slot.SCOOTER, "AB12CD", nil))
+	bySlot, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	clock.Advance(time.Hour * 2)
+
+	// billed from the recorded in-time with the tariff
+	receipt, err := plot.LostTicketExit(LostTicket{Plate: "ab-12-cd"})
+	if err != nil || receipt.GetCost() != usd(20) || receipt.GetPlate() != "AB12CD" {
+		t.Fatalf("lost ticket exit by plate failed %v %v", receipt, err)
+	}
+	if unparked, ok := events[len(events)-1].(VehicleUnparked); !ok || !unparked.LostTicket {
+		t.Errorf("unexpected event %v", events[len(events)-1])
+	}
+	receipt, err = plot.LostTicketExit(LostTicket{VehicleType: slot.SCOOTER, SlotNumber: bySlot.GetNumber()})
+	if err != nil || receipt.GetCost() != usd(20) {
+		t.Fatalf("lost ticket exit by slot failed %v %v", receipt, err)
+	}
+
+	// the original tickets are voided
+	for _, ticket := range []slot.Ticket{byPlate, bySlot} {
+		if _, err := plot.UnPark(ticket); !errors.Is(err, ErrTicketVoided) {
+			t.Errorf("expected ErrTicketVoided, got %v", err)
+		}
+	}
+	if _, err := plot.LostTicketExit(LostTicket{Plate: "AB12CD"}); !errors.Is(err, ErrPlateNotFound) {
+		t.Errorf("expected ErrPlateNotFound, got %v", err)
+	}
+	if _, err := plot.LostTicketExit(LostTicket{VehicleType: slot.SCOOTER, SlotNumber: 0}); !errors.Is(err, ErrSlotFree) {
+		t.Errorf("expected ErrSlotFree, got %v", err)
+	}
+	if _, err := plot.LostTicketExit(LostTicket{VehicleType: slot.SCOOTER, SlotNumber: 7}); !errors.Is(err, ErrSlotNotFound) {
+		t.Errorf("expected ErrSlotNotFound, got %v", err)
+	}
+	if report, _ := plot.Report(); report.Total != usd(40) || report.Receipts != 2 {
+		t.Errorf("lost ticket exits must be booked %+v", report)
+	}
+}
+
+func TestLostTicketPenalty(t *testing.T) {
+	// the tariff with a minimum charge of 50
+	penalty := tariff.NewMaxTariffMatcher()
+	penalty.Append(getMallTariff()[slot.SCOOTER])
+	penalty.Append(tariff.NewHourInterval(usd(50), tariff.NewTimeConstraint(0, math.MaxFloat64)))
+	config := NewParkingConfig(slot.SCOOTER, 2, getMallTariff()[slot.SCOOTER])
+	if err := config.SetLostTicketTariff(penalty); err != nil {
+		t.Fatalf("penalty rejected %v", err)
+	}
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot([]*ParkingConfig{config}, WithClock(clock))
+
+	plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	clock.Advance(time.Hour * 2)
+	receipt, err := plot.LostTicketExit(LostTicket{VehicleType: slot.SCOOTER, SlotNumber: 0})
+	if err != nil || receipt.GetCost() != usd(50) {
+		t.Errorf("expected the minimum charge, got %v %v", receipt, err)
+	}
+	clock.Advance(time.Hour * 4)
+	receipt, err = plot.LostTicketExit(LostTicket{VehicleType: slot.SCOOTER, SlotNumber: 1})
+	if err != nil || receipt.GetCost() != usd(60) {
+		t.Errorf("expected the tariff, got %v %v", receipt, err)
+	}
+}
+
+func TestLostTicketPenaltyCurrency(t *testing.T) {
+	config := NewParkingConfig(slot.SCOOTER, 2, getMallTariff()[slot.SCOOTER])
+	if err := config.SetLostTicketTariff(euroTariff(5000)); !errors.Is(err, money.ErrCurrencyMismatch) || config.GetLostTicketTariff() != nil {
+		t.Fatalf("expected a currency mismatch, got %v", err)
+	}
+
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("open store failed %v", err)
+	}
+	plot, err := OpenParkingLot([]*ParkingConfig{config}, store, WithClock(clock))
+	if err != nil {
+		t.Fatalf("open parking lot failed %v", err)
+	}
+	plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	clock.Advance(time.Hour * 2)
+	receipt, err := plot.LostTicketExit(LostTicket{VehicleType: slot.SCOOTER, SlotNumber: 0})
+	if err != nil || receipt.GetCost() != usd(20) {
+		t.Errorf("expected the tariff, got %v %v", receipt, err)
+	}
+	store.Close()
+
+	// the journal of the exit replays in the currency of the lot
+	store, err = NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("reopen store failed %v", err)
+	}
+	defer store.Close()
+	plot, err = OpenParkingLot([]*ParkingConfig{config}, store, WithClock(clock))
+	if err != nil {
+		t.Fatalf("reopen parking lot failed %v", err)
+	}
+	if report, err := plot.Report(); err != nil || report.Total != usd(20) || report.Receipts != 1 {
+		t.Errorf("lost ticket exit must be recovered %+v %v", report, err)
+	}
+}
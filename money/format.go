@@ -0,0 +1,24 @@
+package money
+
+import (
+	"strings"
+)
+
+// symbols : currencies formatted with a symbol in front of the amount
+var symbols = map[string]string{
+	"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥", "INR": "₹", "KRW": "₩", "CNY": "CN¥", "AUD": "A$", "CAD": "CA$",
+}
+
+// Format renders the amount for people, with the currency symbol where one is known,
+// "$10.50", "¥400", "1.234 KWD"
+func (money Money) Format() string {
+	symbol, ok := symbols[money.currency]
+	if !ok {
+		return money.String()
+	}
+	text := strings.TrimSuffix(money.String(), " "+money.currency)
+	if strings.HasPrefix(text, "-") {
+		return "-" + symbol + text[1:]
+	}
+	return symbol + text
+}
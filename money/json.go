@@ -0,0 +1,29 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MarshalJSON writes the exact amount and currency as one string, "10.50 USD"
+func (money Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(money.String())
+}
+
+func (money *Money) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("%w: money is a string like %q", ErrInvalidAmount, "10.50 USD")
+	}
+	fields := strings.Fields(text)
+	if len(fields) != 2 {
+		return fmt.Errorf("%w %q: money is a string like %q", ErrInvalidAmount, text, "10.50 USD")
+	}
+	parsed, err := Parse(fields[0], fields[1])
+	if err != nil {
+		return err
+	}
+	*money = parsed
+	return nil
+}
@@ -0,0 +1,246 @@
+// Package money represents amounts as integer minor units of a currency, e.g. cents of USD,
+// so sums are exact. Conversions from float64 and divisions round with an explicit RoundingMode.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// exponents : number of minor unit digits of currencies that do not use 2
+var exponents = map[string]int{
+	"BHD": 3, "CLP": 0, "IQD": 3, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "LYD": 3, "OMR": 3, "TND": 3, "UGX": 0, "VND": 0, "XAF": 0, "XOF": 0,
+}
+
+// Exponent returns the number of minor unit digits of the currency, 2 when not listed
+func Exponent(currency string) int {
+	if exponent, ok := exponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+func minorPerMajor(currency string) int64 {
+	return int64(math.Pow10(Exponent(currency)))
+}
+
+// RoundingMode : how an amount between two minor units is rounded
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds halves away from zero
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds halves to the even neighbour, bankers rounding
+	RoundHalfEven
+	// RoundDown truncates towards zero
+	RoundDown
+	// RoundUp rounds away from zero
+	RoundUp
+)
+
+// Money : amount in minor units of the currency, the zero value has no currency and
+// adds to any currency
+type Money struct {
+	amount   int64
+	currency string
+}
+
+// New creates money from minor units, New(1050, "USD") is 10.50 USD
+func New(amount int64, currency string) Money {
+	return Money{amount: amount, currency: currency}
+}
+
+// FromMajor converts a float amount in major units, rounding to the minor unit with the mode
+func FromMajor(major float64, currency string, mode RoundingMode) Money {
+	return Money{amount: roundFloat(major*float64(minorPerMajor(currency)), mode), currency: currency}
+}
+
+// Parse reads an exact decimal amount in major units, "10.5" or "-3", digits beyond
+// the minor unit of the currency are rejected
+func Parse(value string, currency string) (Money, error) {
+	text := strings.TrimSpace(value)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+	whole, fraction := text, ""
+	if i := strings.IndexByte(text, '.'); i >= 0 {
+		whole, fraction = text[:i], text[i+1:]
+	}
+	exponent := Exponent(currency)
+	if whole == "" || len(fraction) > exponent || strings.ContainsAny(whole+fraction, "+-") {
+		return Money{}, fmt.Errorf("%w %q for %s", ErrInvalidAmount, value, currency)
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w %q for %s", ErrInvalidAmount, value, currency)
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{amount: amount, currency: currency}, nil
+}
+
+// Amount returns the minor units
+func (money Money) Amount() int64 {
+	return money.amount
+}
+
+func (money Money) Currency() string {
+	return money.currency
+}
+
+// Major returns the amount in major units, for display and reporting only
+func (money Money) Major() float64 {
+	return float64(money.amount) / float64(minorPerMajor(money.currency))
+}
+
+func (money Money) IsZero() bool {
+	return money.amount == 0
+}
+
+func (money Money) IsNegative() bool {
+	return money.amount < 0
+}
+
+// SameCurrency reports whether both amounts can be added, the zero value matches any currency
+func (money Money) SameCurrency(other Money) bool {
+	return money.currency == other.currency || money == (Money{}) || other == (Money{})
+}
+
+func (money Money) currencyOf(other Money) string {
+	if money.currency == "" {
+		return other.currency
+	}
+	return money.currency
+}
+
+// Add panics with ErrCurrencyMismatch when the currencies differ, check SameCurrency first
+// where the currencies are not known to match
+func (money Money) Add(other Money) Money {
+	money.mustMatch(other)
+	return Money{amount: money.amount + other.amount, currency: money.currencyOf(other)}
+}
+
+func (money Money) Sub(other Money) Money {
+	money.mustMatch(other)
+	return Money{amount: money.amount - other.amount, currency: money.currencyOf(other)}
+}
+
+func (money Money) mustMatch(other Money) {
+	if !money.SameCurrency(other) {
+		panic(fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, money.currency, other.currency))
+	}
+}
+
+// Mul multiplies by a whole quantity, exact
+func (money Money) Mul(quantity int64) Money {
+	return Money{amount: money.amount * quantity, currency: money.currency}
+}
+
+// MulRat multiplies by numerator / denominator, rounding the result with the mode
+func (money Money) MulRat(numerator int64, denominator int64, mode RoundingMode) Money {
+	return Money{amount: divide(money.amount*numerator, denominator, mode), currency: money.currency}
+}
+
+// RoundTo rounds the amount to a multiple of the increment with the mode,
+// an increment of New(5, "CHF") rounds to the nearest 0.05
+func (money Money) RoundTo(increment Money, mode RoundingMode) Money {
+	money.mustMatch(increment)
+	if increment.amount <= 0 {
+		return money
+	}
+	return Money{amount: divide(money.amount, increment.amount, mode) * increment.amount, currency: money.currencyOf(increment)}
+}
+
+// Cmp returns -1, 0 or 1, the currencies must match
+func (money Money) Cmp(other Money) int {
+	money.mustMatch(other)
+	switch {
+	case money.amount < other.amount:
+		return -1
+	case money.amount > other.amount:
+		return 1
+	}
+	return 0
+}
+
+// String formats the amount with the minor unit digits of the currency, "10.50 USD"
+func (money Money) String() string {
+	exponent := Exponent(money.currency)
+	amount := money.amount
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	text := strconv.FormatInt(amount/minorPerMajor(money.currency), 10)
+	if exponent > 0 {
+		text += fmt.Sprintf(".%0*d", exponent, amount%minorPerMajor(money.currency))
+	}
+	if money.currency == "" {
+		return sign + text
+	}
+	return sign + text + " " + money.currency
+}
+
+// Sum adds the amounts, they must share the currency
+func Sum(values ...Money) Money {
+	var sum Money
+	for _, value := range values {
+		sum = sum.Add(value)
+	}
+	return sum
+}
+
+// divide rounds numerator / denominator with the mode
+func divide(numerator int64, denominator int64, mode RoundingMode) int64 {
+	if denominator < 0 {
+		numerator, denominator = -numerator, -denominator
+	}
+	quotient, remainder := numerator/denominator, numerator%denominator
+	if remainder == 0 {
+		return quotient
+	}
+	sign := int64(1)
+	if numerator < 0 {
+		sign, remainder = -1, -remainder
+	}
+	switch mode {
+	case RoundDown:
+		return quotient
+	case RoundUp:
+		return quotient + sign
+	case RoundHalfEven:
+		if 2*remainder > denominator || (2*remainder == denominator && quotient%2 != 0) {
+			return quotient + sign
+		}
+		return quotient
+	}
+	if 2*remainder >= denominator {
+		return quotient + sign
+	}
+	return quotient
+}
+
+func roundFloat(value float64, mode RoundingMode) int64 {
+	switch mode {
+	case RoundDown:
+		return int64(math.Trunc(value))
+	case RoundUp:
+		if value < 0 {
+			return int64(math.Floor(value))
+		}
+		return int64(math.Ceil(value))
+	case RoundHalfEven:
+		return int64(math.RoundToEven(value))
+	}
+	return int64(math.Round(value))
+}
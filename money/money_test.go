@@ -0,0 +1,126 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestFromMajor(t *testing.T) {
+	cases := []struct {
+		major    float64
+		currency string
+		mode     RoundingMode
+		amount   int64
+	}{
+		{10, "USD", RoundHalfUp, 1000},
+		{0.1 + 0.2, "USD", RoundHalfUp, 30},
+		{2.675, "USD", RoundHalfUp, 268},
+		{-2.5, "JPY", RoundHalfUp, -3},
+		{2.5, "JPY", RoundHalfEven, 2},
+		{2.99, "JPY", RoundDown, 2},
+		{2.01, "JPY", RoundUp, 3},
+		{1.2345, "KWD", RoundHalfUp, 1235},
+	}
+	for _, c := range cases {
+		if money := FromMajor(c.major, c.currency, c.mode); money.Amount() != c.amount || money.Currency() != c.currency {
+			t.Errorf("FromMajor(%v, %s) expected %d, got %v", c.major, c.currency, c.amount, money)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	if money, err := Parse("10.5", "USD"); err != nil || money != New(1050, "USD") {
+		t.Errorf("parse failed %v %v", money, err)
+	}
+	if money, err := Parse("-3", "JPY"); err != nil || money != New(-3, "JPY") {
+		t.Errorf("parse failed %v %v", money, err)
+	}
+	for _, value := range []string{"10.505", "1.5", "abc", ".5", "1.-5", ""} {
+		currency := "USD"
+		if value == "1.5" {
+			currency = "JPY"
+		}
+		if _, err := Parse(value, currency); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("expected ErrInvalidAmount for %q, got %v", value, err)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	// sums of thousands of transactions stay exact
+	var sum Money
+	for i := 0; i < 10000; i++ {
+		sum = sum.Add(FromMajor(0.1, "USD", RoundHalfUp))
+	}
+	if sum != New(100000, "USD") {
+		t.Errorf("sum drifted %v", sum)
+	}
+	if Sum(New(100, "EUR"), New(250, "EUR")).Sub(New(50, "EUR")) != New(300, "EUR") {
+		t.Errorf("sum failed")
+	}
+	if New(1000, "USD").Mul(3) != New(3000, "USD") {
+		t.Errorf("mul failed")
+	}
+	// 10.00 * 1/3
+	if New(1000, "USD").MulRat(1, 3, RoundHalfUp) != New(333, "USD") || New(1000, "USD").MulRat(1, 3, RoundUp) != New(334, "USD") {
+		t.Errorf("mul rat failed")
+	}
+	if New(250, "USD").MulRat(1, 100, RoundHalfEven) != New(2, "USD") || New(-250, "USD").MulRat(1, 100, RoundHalfUp) != New(-3, "USD") {
+		t.Errorf("mul rat rounding failed")
+	}
+	if New(1234, "CHF").RoundTo(New(5, "CHF"), RoundHalfUp) != New(1235, "CHF") || New(1232, "CHF").RoundTo(New(5, "CHF"), RoundHalfUp) != New(1230, "CHF") {
+		t.Errorf("round to 0.05 failed")
+	}
+	if New(1250, "USD").RoundTo(New(100, "USD"), RoundHalfEven) != New(1200, "USD") || New(1201, "USD").RoundTo(New(100, "USD"), RoundUp) != New(1300, "USD") {
+		t.Errorf("round to whole unit failed")
+	}
+	if New(100, "USD").Cmp(New(200, "USD")) != -1 || New(100, "USD").Cmp(New(100, "USD")) != 0 {
+		t.Errorf("cmp failed")
+	}
+}
+
+func TestCurrencyMismatch(t *testing.T) {
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrCurrencyMismatch) {
+			t.Errorf("expected ErrCurrencyMismatch panic, got %v", err)
+		}
+	}()
+	if New(100, "USD").SameCurrency(New(100, "EUR")) {
+		t.Errorf("same currency failed")
+	}
+	New(100, "USD").Add(New(100, "EUR"))
+}
+
+func TestString(t *testing.T) {
+	cases := map[string]Money{
+		"10.50 USD": New(1050, "USD"),
+		"-0.05 USD": New(-5, "USD"),
+		"400 JPY":   New(400, "JPY"),
+		"1.234 KWD": New(1234, "KWD"),
+		"0.00":      {},
+	}
+	for expected, money := range cases {
+		if money.String() != expected {
+			t.Errorf("expected %s, got %s", expected, money.String())
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, _ := json.Marshal(New(1050, "USD"))
+	if string(data) != `"10.50 USD"` {
+		t.Errorf("unexpected json %s", data)
+	}
+	var money Money
+	if err := json.Unmarshal(data, &money); err != nil || money != New(1050, "USD") {
+		t.Errorf("round trip failed %v %v", money, err)
+	}
+	if err := json.Unmarshal([]byte(`10.5`), &money); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("expected ErrInvalidAmount, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`"10.5"`), &money); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("expected ErrInvalidAmount, got %v", err)
+	}
+}
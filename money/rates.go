@@ -0,0 +1,71 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+var ErrNoRate = errors.New("no conversion rate")
+
+// RateProvider gives the exact rate to convert one major unit of from into to
+type RateProvider interface {
+	Rate(from string, to string) (*big.Rat, error)
+}
+
+// StaticRates : fixed table of rates, the inverse of a rate is used when only the reverse is set
+type StaticRates struct {
+	mutex sync.RWMutex
+	rates map[[2]string]*big.Rat
+}
+
+// Set stores the rate as an exact decimal, Set("EUR", "USD", "1.0825")
+func (staticRates *StaticRates) Set(from string, to string, rate string) error {
+	value, ok := new(big.Rat).SetString(rate)
+	if !ok || value.Sign() <= 0 {
+		return fmt.Errorf("invalid rate %q for %s to %s", rate, from, to)
+	}
+	staticRates.mutex.Lock()
+	defer staticRates.mutex.Unlock()
+	staticRates.rates[[2]string{from, to}] = value
+	return nil
+}
+
+func (staticRates *StaticRates) Rate(from string, to string) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+	staticRates.mutex.RLock()
+	defer staticRates.mutex.RUnlock()
+	if rate, ok := staticRates.rates[[2]string{from, to}]; ok {
+		return new(big.Rat).Set(rate), nil
+	}
+	if rate, ok := staticRates.rates[[2]string{to, from}]; ok {
+		return new(big.Rat).Inv(rate), nil
+	}
+	return nil, fmt.Errorf("%w from %s to %s", ErrNoRate, from, to)
+}
+
+func NewStaticRates() *StaticRates {
+	return &StaticRates{rates: make(map[[2]string]*big.Rat)}
+}
+
+// Convert changes the currency of the amount with the provider rate, rounding to the minor unit of to
+func Convert(money Money, to string, provider RateProvider, mode RoundingMode) (Money, error) {
+	if money.currency == to {
+		return money, nil
+	}
+	rate, err := provider.Rate(money.currency, to)
+	if err != nil {
+		return Money{}, err
+	}
+	// minor units of to = amount * rate * 10^exponent(to) / 10^exponent(from)
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(money.amount), rate)
+	value.Mul(value, new(big.Rat).SetInt64(minorPerMajor(to)))
+	value.Quo(value, new(big.Rat).SetInt64(minorPerMajor(money.currency)))
+	if !value.Num().IsInt64() || !value.Denom().IsInt64() {
+		return Money{}, fmt.Errorf("%w: %v to %s overflows", ErrInvalidAmount, money, to)
+	}
+	return Money{amount: divide(value.Num().Int64(), value.Denom().Int64(), mode), currency: to}, nil
+}
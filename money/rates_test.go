@@ -0,0 +1,46 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	rates := NewStaticRates()
+	rates.Set("EUR", "USD", "1.0825")
+	rates.Set("USD", "JPY", "150")
+
+	if converted, err := Convert(New(1000, "EUR"), "USD", rates, RoundHalfUp); err != nil || converted != New(1083, "USD") {
+		t.Errorf("convert failed %v %v", converted, err)
+	}
+	// inverse rate : 10.83 / 1.0825 = 10.0046..
+	if converted, err := Convert(New(1083, "USD"), "EUR", rates, RoundHalfUp); err != nil || converted != New(1000, "EUR") {
+		t.Errorf("inverse convert failed %v %v", converted, err)
+	}
+	// minor units differ : 10.00 USD is 1500 JPY
+	if converted, err := Convert(New(1000, "USD"), "JPY", rates, RoundHalfUp); err != nil || converted != New(1500, "JPY") {
+		t.Errorf("convert to JPY failed %v %v", converted, err)
+	}
+	if _, err := Convert(New(1000, "EUR"), "INR", rates, RoundHalfUp); !errors.Is(err, ErrNoRate) {
+		t.Errorf("expected ErrNoRate, got %v", err)
+	}
+	if err := rates.Set("EUR", "GBP", "-1"); err == nil {
+		t.Errorf("negative rate accepted")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := map[string]Money{
+		"$10.50":    New(1050, "USD"),
+		"-€0.05":    New(-5, "EUR"),
+		"¥400":      New(400, "JPY"),
+		"₹40.00":    New(4000, "INR"),
+		"1.234 KWD": New(1234, "KWD"),
+		"12.00 CHF": New(1200, "CHF"),
+	}
+	for expected, money := range cases {
+		if money.Format() != expected {
+			t.Errorf("expected %s, got %s", expected, money.Format())
+		}
+	}
+}
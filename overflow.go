@@ -0,0 +1,84 @@
+package parking
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hbkkanna/parking/slot"
+)
+
+// tariffs of a vehicle parked in the slot of another vehicle type
+const (
+	BillVehicleTariff = "Vehicle"
+	BillSlotTariff    = "Slot"
+)
+
+var (
+	ErrIncompatibleSize = errors.New("slot too small for vehicle")
+	ErrInvalidOverflow  = errors.New("invalid overflow")
+)
+
+// Overflow : a vehicle type may park in the slots of the slot type when its own slots are taken.
+// Bill is BillVehicleTariff, the default, or BillSlotTariff. Reserve slots of the slot type
+// are kept free for its own vehicles.
+type Overflow struct {
+	SlotType int
+	Bill     string
+	Reserve  int
+}
+
+// tariffType returns the vehicle type whose tariff bills the vehicle in the slot
+func (overflow Overflow) tariffType(vehicleType int) int {
+	if overflow.Bill == BillSlotTariff {
+		return overflow.SlotType
+	}
+	return vehicleType
+}
+
+// Validate checks the rule for the vehicle type, the slot type must be registered with
+// the same or a larger size class
+func (overflow Overflow) Validate(vehicleType int) error {
+	if overflow.Bill != "" && overflow.Bill != BillVehicleTariff && overflow.Bill != BillSlotTariff {
+		return fmt.Errorf("%w: unknown bill %q", ErrInvalidOverflow, overflow.Bill)
+	}
+	if overflow.Reserve < 0 {
+		return fmt.Errorf("%w: reserve %d must not be negative", ErrInvalidOverflow, overflow.Reserve)
+	}
+	if overflow.SlotType == vehicleType {
+		return fmt.Errorf("%w: vehicle type %d overflows into its own slots", ErrInvalidOverflow, vehicleType)
+	}
+	vehicle, ok := slot.GetVehicleType(vehicleType)
+	if !ok {
+		return fmt.Errorf("%w %d", ErrUnknownVehicle, vehicleType)
+	}
+	slotType, ok := slot.GetVehicleType(overflow.SlotType)
+	if !ok {
+		return fmt.Errorf("%w %d", ErrUnknownVehicle, overflow.SlotType)
+	}
+	if slotType.Size < vehicle.Size {
+		return fmt.Errorf("%w: %s %v in %s %v slot", ErrIncompatibleSize, vehicle.Name, vehicle.Size, slotType.Name, slotType.Size)
+	}
+	return nil
+}
+
+// AllowOverflow adds an overflow rule of the vehicle type, rules are tried in the order they are added
+func (parkingConfig *ParkingConfig) AllowOverflow(overflow Overflow) error {
+	if err := overflow.Validate(parkingConfig.vehicleType); err != nil {
+		return err
+	}
+	parkingConfig.overflow = append(parkingConfig.overflow, overflow)
+	return nil
+}
+
+func (parkingConfig *ParkingConfig) GetOverflow() []Overflow {
+	return parkingConfig.overflow
+}
+
+func getOverflowMap(configs []*ParkingConfig) map[int][]Overflow {
+	overflows := make(map[int][]Overflow)
+	for _, v := range configs {
+		if len(v.overflow) > 0 {
+			overflows[v.vehicleType] = v.overflow
+		}
+	}
+	return overflows
+}
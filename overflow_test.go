@@ -0,0 +1,86 @@
+package parking
+
+import (
+	"errors"
+	"github.com/hbkkanna/parking/slot"
+	"testing"
+	"time"
+)
+
+func overflowLot(t *testing.T, clock Clock, bill string) Parkinglot {
+	mallTariff := getMallTariff()
+	scooter := NewParkingConfig(slot.SCOOTER, 1, mallTariff[slot.SCOOTER])
+	suv := NewParkingConfig(slot.SUV, 3, mallTariff[slot.SUV])
+	if err := scooter.AllowOverflow(Overflow{SlotType: slot.SUV, Bill: bill, Reserve: 1}); err != nil {
+		t.Fatalf("overflow rule failed %v", err)
+	}
+	return NewParkingLot([]*ParkingConfig{scooter, suv}, WithClock(clock))
+}
+
+func TestOverflow(t *testing.T) {
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := overflowLot(t, clock, BillVehicleTariff)
+	plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+
+	// scooter slots are full, scooters use suv slots leaving one free
+	var overflowed []slot.Ticket
+	for i := 0; i < 2; i++ {
+		ticket, err := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+		if err != nil || ticket.GetVehicleType() != slot.SUV {
+			t.Fatalf("overflow park failed %v", err)
+		}
+		overflowed = append(overflowed, ticket)
+	}
+	if _, err := plot.Park(slot.NewRoadVehicle(slot.SCOOTER)); !errors.Is(err, ErrNoSpace) {
+		t.Errorf("reserve must be kept, got %v", err)
+	}
+	var noSpace *NoSpaceError
+	if _, err := plot.Park(slot.NewRoadVehicle(slot.SCOOTER)); !errors.As(err, &noSpace) || noSpace.VehicleType != slot.SCOOTER {
+		t.Errorf("rejection must name the vehicle type, got %v", err)
+	}
+	if _, err := plot.Park(slot.NewRoadVehicle(slot.SUV)); err != nil {
+		t.Errorf("suv must park in the reserve %v", err)
+	}
+
+	// billed with the scooter tariff, booked as scooter revenue
+	clock.Advance(time.Hour * 2)
+	if quote, err := plot.QuoteTicket(overflowed[0], clock.Now()); err != nil || quote.GetCost() != usd(20) {
+		t.Errorf("unexpected quote %v %v", quote, err)
+	}
+	receipt, err := plot.UnPark(overflowed[0])
+	if err != nil || receipt.GetCost() != usd(20) {
+		t.Fatalf("unexpected receipt %v %v", receipt, err)
+	}
+	report, _ := plot.Report()
+	if report.Revenue[slot.SCOOTER] != usd(20) || report.Revenue[slot.SUV] != usd(0) {
+		t.Errorf("unexpected revenue %v", report.Revenue)
+	}
+}
+
+func TestOverflowSlotTariff(t *testing.T) {
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := overflowLot(t, clock, BillSlotTariff)
+	plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	clock.Advance(time.Hour * 2)
+	receipt, err := plot.UnPark(ticket)
+	if err != nil || receipt.GetCost() != usd(40) {
+		t.Errorf("expected the suv tariff, got %v %v", receipt, err)
+	}
+}
+
+func TestOverflowValidate(t *testing.T) {
+	config := NewParkingConfig(slot.TRUCK, 1, getMallTariff()[slot.TRUCK])
+	if err := config.AllowOverflow(Overflow{SlotType: slot.SCOOTER}); !errors.Is(err, ErrIncompatibleSize) {
+		t.Errorf("expected ErrIncompatibleSize, got %v", err)
+	}
+	config = NewParkingConfig(slot.SCOOTER, 1, getMallTariff()[slot.SCOOTER])
+	for _, overflow := range []Overflow{{SlotType: slot.SUV, Bill: "Cheapest"}, {SlotType: slot.SUV, Reserve: -1}, {SlotType: slot.SCOOTER}} {
+		if err := config.AllowOverflow(overflow); !errors.Is(err, ErrInvalidOverflow) {
+			t.Errorf("expected ErrInvalidOverflow for %+v, got %v", overflow, err)
+		}
+	}
+	if err := config.AllowOverflow(Overflow{SlotType: 99}); !errors.Is(err, ErrUnknownVehicle) {
+		t.Errorf("expected ErrUnknownVehicle, got %v", err)
+	}
+}
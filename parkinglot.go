@@ -3,82 +3,311 @@ package parking
 import (
 	"errors"
 	"fmt"
+	"github.com/hbkkanna/parking/charges"
+	"github.com/hbkkanna/parking/money"
 	"github.com/hbkkanna/parking/slot"
 	tariff2 "github.com/hbkkanna/parking/tariff"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Parkinglot interface {
 	Park(vehicle slot.Vehicle) (slot.Ticket, error)
 	UnPark(ticket slot.Ticket) (slot.Receipt, error)
+	VoidTicket(ticket slot.Ticket) error
+	LostTicketExit(lost LostTicket) (slot.Receipt, error)
+	FindByPlate(plate string) (slot.Ticket, error)
+	Quote(vehicleType int, inTime time.Time, outTime time.Time) (Quote, error)
+	QuoteTicket(ticket slot.Ticket, at time.Time) (Quote, error)
+	Checkpoint() error
+	Report() (Report, error)
+	ConsolidatedReport(currency string, provider money.RateProvider) (Report, error)
 }
 
+// VehicleParkingLot is safe for concurrent use, slots of each vehicle type are guarded
+// by their own lock so gates parking different vehicle types do not block each other.
 type VehicleParkingLot struct {
-	slots      map[int][]slot.Slot
-	tariff     map[int]tariff2.Tariff
-	ticketCnt  int
-	receiptCnt int
+	slots        map[int][]slot.Slot
+	tariff       map[int]tariff2.Tariff
+	lostTicket   map[int]tariff2.Tariff
+	overflow     map[int][]Overflow
+	allocators   map[int]SlotAllocator
+	invalid      map[int]error
+	charges      map[int]charges.Pipeline
+	locks        map[int]*sync.Mutex
+	clock        Clock
+	events       EventSink
+	tickets      *ticketRegistry
+	revenue      map[int]*revenue
+	store        Store
+	journalMutex sync.Mutex
+	sequence     int64
+	ticketCnt    int64
+	receiptCnt   int64
 }
 
+// Park issues a ticket for a free slot of the vehicle type, when they are taken the vehicle
+// overflows into larger slots in the order of its overflow rules
 func (parkingLot *VehicleParkingLot) Park(vehicle slot.Vehicle) (slot.Ticket, error) {
-	freeSlot, err := parkingLot.findFreeSlot(vehicle)
-	if err != nil {
-		fmt.Println(err)
+	vehicleType := vehicle.GetVehicleType()
+	// a parked plate is refused before any slot is looked for, even in a full lot
+	if err := parkingLot.tickets.reserve(vehicle.GetPlate()); err != nil {
+		parkingLot.events.Publish(ParkRejected{VehicleType: vehicleType, Err: err})
 		return nil, err
 	}
-	freeSlot.SetInTime(time.Now())
-	parkingLot.ticketCnt++
-	ticket := slot.NewTicket(parkingLot.ticketCnt, freeSlot)
-	return ticket, nil
+	ticket, err := parkingLot.parkIn(vehicle, vehicleType, 0, vehicleType)
+	if errors.Is(err, ErrNoSpace) {
+		for _, overflow := range parkingLot.overflow[vehicleType] {
+			overflowTicket, overflowErr := parkingLot.parkIn(vehicle, overflow.SlotType, overflow.Reserve, overflow.tariffType(vehicleType))
+			if !errors.Is(overflowErr, ErrNoSpace) {
+				ticket, err = overflowTicket, overflowErr
+				break
+			}
+		}
+	}
+	if err != nil {
+		parkingLot.tickets.release(vehicle.GetPlate())
+	}
+	if errors.Is(err, ErrNoSpace) {
+		parkingLot.events.Publish(ParkRejected{VehicleType: vehicleType, Err: err})
+	}
+	return ticket, err
 }
 
-//testing purpose to set time
-func (parkingLot *VehicleParkingLot) park(vehicle slot.Vehicle, inTime time.Time) (slot.Ticket, error) {
-	fmt.Printf(" -- Park : %v -- \n", vehicle)
-	ticket, err := parkingLot.Park(vehicle)
+// parkIn issues a ticket for a free slot of the slot type leaving the reserve free, the stay is
+// billed with the tariff of the tariff type. The plate of the vehicle is reserved by Park.
+func (parkingLot *VehicleParkingLot) parkIn(vehicle slot.Vehicle, slotType int, reserve int, tariffType int) (slot.Ticket, error) {
+	if err := parkingLot.invalid[tariffType]; err != nil {
+		return nil, err
+	}
+	unlock := parkingLot.lock(slotType)
+	defer unlock()
+	freeSlot, err := parkingLot.findFreeSlot(slotType, reserve)
 	if err != nil {
 		return nil, err
 	}
-	ticket.SetInTime(inTime)
-	fmt.Println(ticket)
+	plate := vehicle.GetPlate()
+	inTime := parkingLot.clock.Now()
+	ticketNumber := atomic.AddInt64(&parkingLot.ticketCnt, 1)
+	record := Record{Op: OpPark, TicketNumber: int(ticketNumber),
+		VehicleType: slotType, SlotNumber: freeSlot.GetNumber(), Time: inTime, Plate: plate}
+	if tariffType != slotType {
+		record.TariffType = &tariffType
+	}
+	err = parkingLot.journal(record)
+	if err != nil {
+		parkingLot.allocators[slotType].Release(freeSlot.GetNumber())
+		return nil, err
+	}
+	freeSlot.SetInTime(inTime)
+	freeSlot.SetPlate(plate)
+	ticket := slot.NewTicket(int(ticketNumber), freeSlot)
+	parkingLot.tickets.issue(ticket.GetTicketNumber(), slotType, ticket.GetNumber(), plate, tariffType)
+	parkingLot.events.Publish(VehicleParked{
+		TicketNumber: ticket.GetTicketNumber(),
+		VehicleType:  ticket.GetVehicleType(),
+		SlotNumber:   ticket.GetNumber(),
+		Ticket:       ticket,
+	})
 	return ticket, nil
 }
 
 func (parkingLot *VehicleParkingLot) UnPark(ticket slot.Ticket) (slot.Receipt, error) {
-	vehicleSlot, err := parkingLot.getSlot(ticket.GetVehicleType(), ticket.GetNumber())
+	unlock := parkingLot.lock(ticket.GetVehicleType())
+	defer unlock()
+	vehicleSlot, err := parkingLot.getTicketSlot(ticket)
 	if err != nil {
 		return nil, err
 	}
-	err = ticket.SetOutTime(time.Now())
+	return parkingLot.checkout(ticket.GetTicketNumber(), vehicleSlot, OpUnPark)
+}
+
+// checkout bills the stay in the slot, journals the exit, closes the ticket and releases the slot,
+// the vehicle type must be locked. Lost ticket exits are billed with the penalty tariff when the
+// vehicle type has one and void the ticket.
+func (parkingLot *VehicleParkingLot) checkout(ticketNumber int, vehicleSlot slot.Slot, op string) (slot.Receipt, error) {
+	// bill from the lot's own slot, never from the times carried by the presented ticket
+	outTime := parkingLot.clock.Now()
+	parkingTime := slot.NewParkingTime()
+	parkingTime.SetInTime(vehicleSlot.GetInTime())
+	err := parkingTime.SetOutTime(outTime)
 	if err != nil {
 		return nil, err
 	}
-	cost := parkingLot.tariff[ticket.GetVehicleType()].GetCost(ticket)
-	parkingLot.receiptCnt++
-	receipt := slot.NewReceipt(parkingLot.receiptCnt, cost, slot.CloneVehicleSlot(vehicleSlot))
-	fmt.Println(receipt)
-	vehicleSlot.Reset()
+	tariffType := parkingLot.tickets.tariffType(ticketNumber, vehicleSlot.GetVehicleType())
+	vehicleTariff := parkingLot.tariff[tariffType]
+	status := TicketRedeemed
+	if op == OpLostTicket {
+		if penalty, ok := parkingLot.lostTicket[tariffType]; ok {
+			vehicleTariff = penalty
+		}
+		status = TicketVoided
+	}
+	// a stay the tariff does not price leaves the ticket open
+	totals, lines, err := parkingLot.price(tariffType, vehicleTariff, parkingTime)
+	if err != nil {
+		return nil, err
+	}
+	receiptNumber := atomic.AddInt64(&parkingLot.receiptCnt, 1)
+	cost := totals.Gross
+	err = parkingLot.journal(Record{Op: op, TicketNumber: ticketNumber, ReceiptNumber: int(receiptNumber),
+		VehicleType: vehicleSlot.GetVehicleType(), SlotNumber: vehicleSlot.GetNumber(), Time: outTime, Cost: &cost})
+	if err != nil {
+		return nil, err
+	}
+	_ = vehicleSlot.SetOutTime(outTime)
+	receipt := slot.NewTaxedReceipt(int(receiptNumber), totals, slot.CloneVehicleSlot(vehicleSlot), lines...)
+	parkingLot.addRevenue(tariffType, cost)
+	parkingLot.tickets.close(ticketNumber, status)
+	parkingLot.events.Publish(VehicleUnparked{
+		TicketNumber:  ticketNumber,
+		ReceiptNumber: receipt.GetReceiptNumber(),
+		VehicleType:   receipt.GetVehicleType(),
+		SlotNumber:    receipt.GetNumber(),
+		Cost:          receipt.GetCost(),
+		Receipt:       receipt,
+		LostTicket:    op == OpLostTicket,
+	})
+	parkingLot.releaseSlot(vehicleSlot)
 	return receipt, nil
 }
 
-func (parkingLot *VehicleParkingLot) findFreeSlot(vehicle slot.Vehicle) (slot.Slot, error) {
-	slots, ok := parkingLot.slots[vehicle.GetVehicleType()]
-	notAvail := errors.New(fmt.Sprintf(" No space Available"))
+// VoidTicket cancels an issued ticket without billing and releases its slot,
+// the ticket can not be used for UnPark afterwards.
+func (parkingLot *VehicleParkingLot) VoidTicket(ticket slot.Ticket) error {
+	unlock := parkingLot.lock(ticket.GetVehicleType())
+	defer unlock()
+	vehicleSlot, err := parkingLot.getTicketSlot(ticket)
+	if err != nil {
+		return err
+	}
+	err = parkingLot.journal(Record{Op: OpVoid, TicketNumber: ticket.GetTicketNumber(),
+		VehicleType: vehicleSlot.GetVehicleType(), SlotNumber: vehicleSlot.GetNumber(), Time: parkingLot.clock.Now()})
+	if err != nil {
+		return err
+	}
+	parkingLot.tickets.close(ticket.GetTicketNumber(), TicketVoided)
+	parkingLot.releaseSlot(vehicleSlot)
+	return nil
+}
+
+// FindByPlate returns the ticket the plate is parked under, plates match ignoring case, spaces and dashes
+func (parkingLot *VehicleParkingLot) FindByPlate(plate string) (slot.Ticket, error) {
+	record, ok := parkingLot.tickets.find(plate)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPlateNotFound, plate)
+	}
+	unlock := parkingLot.lock(record.vehicleType)
+	defer unlock()
+	// the vehicle may have left since the lookup
+	if err := parkingLot.tickets.validate(record.ticketNumber, record.vehicleType, record.slotNumber); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPlateNotFound, plate)
+	}
+	vehicleSlot, err := parkingLot.getSlot(record.vehicleType, record.slotNumber)
+	if err != nil {
+		return nil, err
+	}
+	return slot.NewTicket(record.ticketNumber, vehicleSlot), nil
+}
+
+// getTicketSlot validates the ticket against the lot records and returns the slot it holds
+func (parkingLot *VehicleParkingLot) getTicketSlot(ticket slot.Ticket) (slot.Slot, error) {
+	err := parkingLot.tickets.validate(ticket.GetTicketNumber(), ticket.GetVehicleType(), ticket.GetNumber())
+	if err != nil {
+		return nil, err
+	}
+	return parkingLot.getSlot(ticket.GetVehicleType(), ticket.GetNumber())
+}
+
+// addRevenue books the cost of a receipt to the vehicle type whose tariff billed it, the slot type must be locked
+func (parkingLot *VehicleParkingLot) addRevenue(vehicleType int, cost money.Money) {
+	takings := parkingLot.revenue[vehicleType]
+	takings.mutex.Lock()
+	defer takings.mutex.Unlock()
+	takings.total = takings.total.Add(cost)
+	takings.receipts++
+}
+
+// price bills the stay with the tariff and the charges of the vehicle type, tariff lines first.
+// Stays the tariff does not price match ErrNotInRange.
+func (parkingLot *VehicleParkingLot) price(vehicleType int, vehicleTariff tariff2.Tariff, parkingTime slot.ParkingTime) (slot.Totals, []slot.ReceiptLine, error) {
+	cost, err := tariff2.Price(vehicleTariff, parkingTime)
+	if err != nil {
+		return slot.Totals{}, nil, err
+	}
+	bill := parkingLot.charges[vehicleType].Apply(cost)
+	lines := append(getReceiptLines(vehicleTariff.Itemize(parkingTime)), getChargeLines(bill.Lines)...)
+	return slot.Totals{Net: bill.Net, Tax: bill.Tax, Gross: bill.Gross()}, lines, nil
+}
+
+func getReceiptLines(items []tariff2.LineItem) []slot.ReceiptLine {
+	var lines []slot.ReceiptLine
+	for _, item := range items {
+		lines = append(lines, slot.ReceiptLine{
+			Kind:        slot.LineTariff,
+			Description: item.Description(),
+			Quantity:    item.Units,
+			Unit:        item.Unit,
+			Amount:      item.Subtotal,
+		})
+	}
+	return lines
+}
+
+func getChargeLines(chargeLines []charges.Line) []slot.ReceiptLine {
+	var lines []slot.ReceiptLine
+	for _, line := range chargeLines {
+		lines = append(lines, slot.ReceiptLine{
+			Kind:        line.Kind,
+			Description: line.Description,
+			Quantity:    1,
+			Amount:      line.Amount,
+		})
+	}
+	return lines
+}
+
+// lock acquires the lock of the vehicle type and returns its release function,
+// unknown vehicle types have no slots to guard.
+func (parkingLot *VehicleParkingLot) lock(vehicleType int) func() {
+	lock, ok := parkingLot.locks[vehicleType]
+	if !ok {
+		return func() {}
+	}
+	lock.Lock()
+	return lock.Unlock
+}
+
+// findFreeSlot takes the slot the allocator of the slot type picks when more than reserve slots
+// are free, the caller releases it when the vehicle does not park
+func (parkingLot *VehicleParkingLot) findFreeSlot(slotType int, reserve int) (slot.Slot, error) {
+	slots, ok := parkingLot.slots[slotType]
+	notAvail := &NoSpaceError{VehicleType: slotType, Capacity: len(slots)}
 	if !ok {
 		return nil, notAvail
 	}
-	for _, v := range slots {
-		if v.IsFree() {
-			return v, nil
-		}
+	allocator := parkingLot.allocators[slotType]
+	if allocator.Free() <= reserve {
+		return nil, notAvail
+	}
+	number, ok := allocator.Allocate()
+	if !ok {
+		return nil, notAvail
 	}
-	return nil, notAvail
+	return slots[number], nil
+}
+
+// releaseSlot frees the slot and hands it back to the allocator, the slot type must be locked
+func (parkingLot *VehicleParkingLot) releaseSlot(vehicleSlot slot.Slot) {
+	vehicleSlot.Reset()
+	parkingLot.allocators[vehicleSlot.GetVehicleType()].Release(vehicleSlot.GetNumber())
 }
 
 func (parkingLot *VehicleParkingLot) getSlot(vehicleType int, number int) (slot.Slot, error) {
 	slots, ok := parkingLot.slots[vehicleType]
-	notFound := errors.New(fmt.Sprintf(" Slot not found for  vehicle type %d , for number %d ", vehicleType, number))
-	if !ok {
+	notFound := &SlotNotFoundError{VehicleType: vehicleType, Number: number}
+	if !ok || number < 0 || number >= len(slots) {
 		return nil, notFound
 	}
 	return slots[number], nil
@@ -88,19 +317,134 @@ type ParkingConfig struct {
 	vehicleType int
 	slotCnt     int
 	tariff      tariff2.Tariff
+	lostTicket  tariff2.Tariff
+	overflow    []Overflow
+	allocator   AllocatorFactory
+	charges     charges.Pipeline
+	currency    string
+}
+
+// GetCurrency returns the currency the vehicle type is billed in, the currency of its tariff
+func (parkingConfig *ParkingConfig) GetCurrency() string {
+	return parkingConfig.currency
+}
+
+// SetCharges applies the taxes, surcharges and rounding of the pipeline after the tariff,
+// the receipt cost is the gross amount. Surcharges and rounding increments have to be in the
+// currency of the tariff, others are rejected with a charges.CurrencyError.
+func (parkingConfig *ParkingConfig) SetCharges(pipeline charges.Pipeline) error {
+	if err := pipeline.CheckCurrency(parkingConfig.currency); err != nil {
+		return err
+	}
+	parkingConfig.charges = pipeline
+	return nil
+}
+
+func (parkingConfig *ParkingConfig) GetCharges() charges.Pipeline {
+	return parkingConfig.charges
+}
+
+// SetLostTicketTariff bills lost ticket exits with the penalty tariff instead of the tariff,
+// e.g. a flat fee or a Max matcher of the tariff and a minimum charge, a penalty in another
+// currency than the tariff matches money.ErrCurrencyMismatch
+func (parkingConfig *ParkingConfig) SetLostTicketTariff(penalty tariff2.Tariff) error {
+	if penalty.GetCurrency() != parkingConfig.currency {
+		return fmt.Errorf("%w: lost ticket tariff in %s, tariff in %s",
+			money.ErrCurrencyMismatch, penalty.GetCurrency(), parkingConfig.currency)
+	}
+	parkingConfig.lostTicket = penalty
+	return nil
+}
+
+func (parkingConfig *ParkingConfig) GetLostTicketTariff() tariff2.Tariff {
+	return parkingConfig.lostTicket
+}
+
+// SetAllocator picks the slots of the vehicle type with the allocators of the factory,
+// e.g. NearestToEntrance or FloorsEvenly, the default is LowestNumberFirst
+func (parkingConfig *ParkingConfig) SetAllocator(factory AllocatorFactory) *ParkingConfig {
+	parkingConfig.allocator = factory
+	return parkingConfig
+}
+
+func (parkingConfig *ParkingConfig) GetAllocator() AllocatorFactory {
+	return parkingConfig.allocator
+}
+
+// Validate checks that the tariffs of the vehicle type are priced in one currency, a tariff mixing
+// currencies can not bill a stay. OpenParkingLot rejects invalid configs, NewParkingLot refuses to
+// park or quote their vehicle type.
+func (parkingConfig *ParkingConfig) Validate() error {
+	for _, vehicleTariff := range []tariff2.Tariff{parkingConfig.tariff, parkingConfig.lostTicket} {
+		if vehicleTariff == nil {
+			continue
+		}
+		for _, issue := range tariff2.Validate(vehicleTariff) {
+			if issue.Kind == tariff2.IssueCurrency {
+				return fmt.Errorf("%w: vehicle type %d tariff in %s, %s", money.ErrCurrencyMismatch,
+					parkingConfig.vehicleType, vehicleTariff.GetCurrency(), issue)
+			}
+		}
+	}
+	return nil
 }
 
 func NewParkingConfig(vehicleType int, slotCnt int, tariff tariff2.Tariff) *ParkingConfig {
-	return &ParkingConfig{vehicleType: vehicleType, slotCnt: slotCnt, tariff: tariff}
+	return &ParkingConfig{vehicleType: vehicleType, slotCnt: slotCnt, tariff: tariff, currency: tariff.GetCurrency()}
+}
+
+// NewNamedParkingConfig creates the validated config of a registered vehicle type by name, e.g. a
+// type added with slot.RegisterVehicleType, unknown names match ErrUnknownVehicle
+func NewNamedParkingConfig(vehicleTypeName string, slotCnt int, tariff tariff2.Tariff) (*ParkingConfig, error) {
+	vehicleType, err := slot.LookupVehicleType(vehicleTypeName)
+	if err != nil {
+		return nil, err
+	}
+	parkingConfig := NewParkingConfig(vehicleType.ID, slotCnt, tariff)
+	if err := parkingConfig.Validate(); err != nil {
+		return nil, err
+	}
+	return parkingConfig, nil
+}
+
+// Option customizes the parking lot created by NewParkingLot
+type Option func(parkingLot *VehicleParkingLot)
+
+// WithEventSink publishes park and unpark events to the sink, the lot is silent by default
+func WithEventSink(events EventSink) Option {
+	return func(parkingLot *VehicleParkingLot) {
+		parkingLot.events = events
+	}
+}
+
+// WithClock replaces the system clock used for entry and exit times
+func WithClock(clock Clock) Option {
+	return func(parkingLot *VehicleParkingLot) {
+		parkingLot.clock = clock
+	}
 }
 
-func NewParkingLot(configs []*ParkingConfig) Parkinglot {
+func NewParkingLot(configs []*ParkingConfig, options ...Option) Parkinglot {
 	slots := getSlotMap(configs)
 	tariffs := getTariffMap(configs)
-	return &VehicleParkingLot{
-		slots:  slots,
-		tariff: tariffs,
+	parkingLot := &VehicleParkingLot{
+		slots:      slots,
+		tariff:     tariffs,
+		lostTicket: getLostTicketMap(configs),
+		overflow:   getOverflowMap(configs),
+		allocators: getAllocatorMap(configs),
+		invalid:    getInvalidMap(configs),
+		charges:    getChargesMap(configs),
+		locks:      getLockMap(configs),
+		clock:      NewRealClock(),
+		events:     NopEventSink{},
+		tickets:    newTicketRegistry(),
+		revenue:    getRevenueMap(configs),
 	}
+	for _, option := range options {
+		option(parkingLot)
+	}
+	return parkingLot
 }
 
 func getSlotMap(configs []*ParkingConfig) map[int][]slot.Slot {
@@ -108,13 +452,36 @@ func getSlotMap(configs []*ParkingConfig) map[int][]slot.Slot {
 	for _, v := range configs {
 		var slots []slot.Slot
 		for j := 0; j < v.slotCnt; j++ {
-			slots = append(slots, slot.NewVehicleSlot(slot.Vehicles[v.vehicleType], j))
+			slots = append(slots, slot.NewVehicleSlot(slot.NewRoadVehicle(v.vehicleType), j))
 		}
 		vehicleSlots[v.vehicleType] = slots
 	}
 	return vehicleSlots
 }
 
+// getInvalidMap returns the validation errors of the vehicle types whose config is invalid
+func getInvalidMap(configs []*ParkingConfig) map[int]error {
+	invalid := make(map[int]error)
+	for _, v := range configs {
+		if err := v.Validate(); err != nil {
+			invalid[v.vehicleType] = err
+		}
+	}
+	return invalid
+}
+
+func getAllocatorMap(configs []*ParkingConfig) map[int]SlotAllocator {
+	allocators := make(map[int]SlotAllocator)
+	for _, v := range configs {
+		factory := v.allocator
+		if factory == nil {
+			factory = LowestNumberFirst()
+		}
+		allocators[v.vehicleType] = factory(v.slotCnt)
+	}
+	return allocators
+}
+
 func getTariffMap(configs []*ParkingConfig) map[int]tariff2.Tariff {
 	tariffs := make(map[int]tariff2.Tariff)
 	for _, v := range configs {
@@ -122,3 +489,38 @@ func getTariffMap(configs []*ParkingConfig) map[int]tariff2.Tariff {
 	}
 	return tariffs
 }
+
+// getLostTicketMap returns the penalty tariffs of the vehicle types that have one
+func getLostTicketMap(configs []*ParkingConfig) map[int]tariff2.Tariff {
+	penalties := make(map[int]tariff2.Tariff)
+	for _, v := range configs {
+		if v.lostTicket != nil {
+			penalties[v.vehicleType] = v.lostTicket
+		}
+	}
+	return penalties
+}
+
+func getChargesMap(configs []*ParkingConfig) map[int]charges.Pipeline {
+	pipelines := make(map[int]charges.Pipeline)
+	for _, v := range configs {
+		pipelines[v.vehicleType] = v.charges
+	}
+	return pipelines
+}
+
+func getLockMap(configs []*ParkingConfig) map[int]*sync.Mutex {
+	locks := make(map[int]*sync.Mutex)
+	for _, v := range configs {
+		locks[v.vehicleType] = &sync.Mutex{}
+	}
+	return locks
+}
+
+func getRevenueMap(configs []*ParkingConfig) map[int]*revenue {
+	revenues := make(map[int]*revenue)
+	for _, v := range configs {
+		revenues[v.vehicleType] = &revenue{total: money.New(0, v.currency)}
+	}
+	return revenues
+}
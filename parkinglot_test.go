@@ -1,14 +1,23 @@
 package parking
 
 import (
+	"errors"
 	"fmt"
+	"github.com/hbkkanna/parking/charges"
+	"github.com/hbkkanna/parking/money"
 	"github.com/hbkkanna/parking/slot"
 	"github.com/hbkkanna/parking/tariff"
 	"math"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
+func usd(major float64) money.Money {
+	return money.FromMajor(major, "USD", money.RoundHalfUp)
+}
+
 // example 1
 
 /*
@@ -23,15 +32,15 @@ func getMallTariff() map[int]tariff.Tariff {
 	tariffs := make(map[int]tariff.Tariff)
 
 	scooterTariff := tariff.NewSingleTariffMatcher()
-	scooterTariff.Append(tariff.NewEveryHour(10))
+	scooterTariff.Append(tariff.NewEveryHour(usd(10)))
 	tariffs[slot.SCOOTER] = scooterTariff
 
 	carTariff := tariff.NewSingleTariffMatcher()
-	carTariff.Append(tariff.NewEveryHour(20))
+	carTariff.Append(tariff.NewEveryHour(usd(20)))
 	tariffs[slot.SUV] = carTariff
 
 	truckTariff := tariff.NewSingleTariffMatcher()
-	truckTariff.Append(tariff.NewEveryHour(50))
+	truckTariff.Append(tariff.NewEveryHour(usd(50)))
 	tariffs[slot.TRUCK] = truckTariff
 	return tariffs
 }
@@ -45,32 +54,29 @@ func SmallParkingLotConfig() []*ParkingConfig {
 
 func TestSMallMallParkingLot(t *testing.T) {
 	message := " ******** Mall parking lot case FAILED ******* "
-	plot := NewParkingLot(SmallParkingLotConfig())
-	lot, _ := plot.(*VehicleParkingLot)
-	ticket1, err1 := lot.park(slot.NewRoadVehicle(slot.SCOOTER), time.Now().Add(-time.Hour*3))
-
-	ticket2, err2 := lot.park(slot.NewRoadVehicle(slot.SCOOTER), time.Now())
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot(SmallParkingLotConfig(), WithClock(clock))
+	ticket1, err1 := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	clock.Advance(time.Hour * 3)
+	ticket2, err2 := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	if err1 != nil || err2 != nil {
+		t.Fatalf(message)
+	}
 
 	// NO SPACE CASE
-	_, err3 := lot.park(slot.NewRoadVehicle(slot.SCOOTER), time.Now())
+	_, err3 := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
 	if err3 == nil {
 		t.Errorf(message)
 	}
 
-	if err1 == nil {
-		receipt, _ := lot.UnPark(ticket1)
-		if receipt.GetCost() != 40 {
-			t.Errorf(message)
-		}
+	// a started hour is billed
+	clock.Advance(time.Minute)
+	if receipt, _ := plot.UnPark(ticket1); receipt.GetCost() != usd(40) {
+		t.Errorf(message)
 	}
-
-	if err2 == nil {
-		receipt, _ := lot.UnPark(ticket2)
-		if receipt.GetCost() != 10 {
-			t.Errorf(message)
-		}
+	if receipt, _ := plot.UnPark(ticket2); receipt.GetCost() != usd(10) {
+		t.Errorf(message)
 	}
-
 }
 
 // example 2
@@ -88,48 +94,36 @@ func TestMallParkingLot(t *testing.T) {
 	message := " ******** Mall parking lot case FAILED ******* "
 
 	fmt.Println("----mall parking  case------ ")
-	plot := NewParkingLot(MallParkingLotConfig())
-	lot, _ := plot.(*VehicleParkingLot)
-	ticket, err := lot.park(slot.NewRoadVehicle(slot.SCOOTER), time.Now().Add(-time.Minute*(60*3+30)))
-	if err == nil {
-		receipt, _ := lot.UnPark(ticket)
-		if receipt.GetCost() != 40 {
-			t.Errorf(message)
-		}
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot(MallParkingLotConfig(), WithClock(clock))
+	if receipt := stay(t, plot, clock, slot.SCOOTER, time.Minute*(60*3+30)); receipt.GetCost() != usd(40) {
+		t.Errorf(message)
 	}
-
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.SUV), time.Now().Add(-time.Minute*(60*6+1)))
-	if err == nil {
-		receipt, _ := lot.UnPark(ticket)
-		if receipt.GetCost() != 140 {
-			t.Errorf(message)
-		}
+	if receipt := stay(t, plot, clock, slot.SUV, time.Minute*(60*6+1)); receipt.GetCost() != usd(140) {
+		t.Errorf(message)
 	}
-
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.TRUCK), time.Now().Add(-time.Minute*(60*1+59)))
-	if err == nil {
-		receipt, _ := lot.UnPark(ticket)
-		if receipt.GetCost() != 100 {
-			t.Errorf(message)
-		}
+	if receipt := stay(t, plot, clock, slot.TRUCK, time.Minute*(60*1+59)); receipt.GetCost() != usd(100) {
+		t.Errorf(message)
 	}
 
 	// slot change case
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.SUV), time.Now().Add(-time.Hour*2))
-	ticket1, err := lot.park(slot.NewRoadVehicle(slot.SUV), time.Now().Add(-time.Hour*2))
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.SUV), time.Now().Add(-time.Hour*2))
-	ticket1, err = lot.park(slot.NewRoadVehicle(slot.SUV), time.Now().Add(-time.Hour*2))
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.SUV), time.Now().Add(-time.Hour*2))
-	ticket1, err = lot.park(slot.NewRoadVehicle(slot.SUV), time.Now().Add(-time.Hour*2))
-	receipt, _ := lot.UnPark(ticket)
-	if receipt.GetCost() != 60 {
+	var tickets []slot.Ticket
+	for i := 0; i < 6; i++ {
+		ticket, err := plot.Park(slot.NewRoadVehicle(slot.SUV))
+		if err != nil {
+			t.Fatalf(message)
+		}
+		tickets = append(tickets, ticket)
+	}
+	clock.Advance(time.Minute * (60*2 + 1))
+	receipt, _ := plot.UnPark(tickets[4])
+	if receipt.GetCost() != usd(60) {
 		t.Errorf(message)
 	}
-	receipt1, _ := lot.UnPark(ticket1)
-	if receipt1.GetCost() != 60 {
+	receipt1, _ := plot.UnPark(tickets[5])
+	if receipt1.GetCost() != usd(60) {
 		t.Errorf(message)
 	}
-
 }
 
 // example 3
@@ -152,16 +146,16 @@ func getStadiumTarrif() map[int]tariff.Tariff {
 	tariffs := make(map[int]tariff.Tariff)
 
 	scooterTariff := tariff.NewMultipleTariffMatcher()
-	scooterTariff.Append(tariff.NewPreviousHourInterval(30, tariff.NewTimeConstraint(tariff.HrtoMinutes(0), tariff.HrtoMinutes(4))))
-	scooterTariff.Append(tariff.NewPreviousHourInterval(60, tariff.NewTimeConstraint(tariff.HrtoMinutes(4), tariff.HrtoMinutes(12))))
-	scooterTariff.Append(tariff.NewEveryHourInInterval(100, tariff.NewTimeConstraint(tariff.HrtoMinutes(12), math.MaxFloat64)))
+	scooterTariff.Append(tariff.NewPreviousHourInterval(usd(30), tariff.NewTimeConstraint(tariff.HrtoMinutes(0), tariff.HrtoMinutes(4))))
+	scooterTariff.Append(tariff.NewPreviousHourInterval(usd(60), tariff.NewTimeConstraint(tariff.HrtoMinutes(4), tariff.HrtoMinutes(12))))
+	scooterTariff.Append(tariff.NewEveryHourInInterval(usd(100), tariff.NewTimeConstraint(tariff.HrtoMinutes(12), math.MaxFloat64)))
 
 	tariffs[slot.SCOOTER] = scooterTariff
 
 	carTariff := tariff.NewMultipleTariffMatcher()
-	carTariff.Append(tariff.NewPreviousHourInterval(60, tariff.NewTimeConstraint(tariff.HrtoMinutes(0), tariff.HrtoMinutes(4))))
-	carTariff.Append(tariff.NewPreviousHourInterval(120, tariff.NewTimeConstraint(tariff.HrtoMinutes(4), tariff.HrtoMinutes(12))))
-	carTariff.Append(tariff.NewEveryHourInInterval(200, tariff.NewTimeConstraint(tariff.HrtoMinutes(12), math.MaxFloat64)))
+	carTariff.Append(tariff.NewPreviousHourInterval(usd(60), tariff.NewTimeConstraint(tariff.HrtoMinutes(0), tariff.HrtoMinutes(4))))
+	carTariff.Append(tariff.NewPreviousHourInterval(usd(120), tariff.NewTimeConstraint(tariff.HrtoMinutes(4), tariff.HrtoMinutes(12))))
+	carTariff.Append(tariff.NewEveryHourInInterval(usd(200), tariff.NewTimeConstraint(tariff.HrtoMinutes(12), math.MaxFloat64)))
 	tariffs[slot.SUV] = carTariff
 
 	return tariffs
@@ -178,43 +172,23 @@ func StadiumParkingLotConfig() []*ParkingConfig {
 func TestStadiumParkingLot(t *testing.T) {
 	message := " ******** Stadium parking lot case FAILED ******* "
 
-	plot := NewParkingLot(StadiumParkingLotConfig())
-	lot, _ := plot.(*VehicleParkingLot)
-	ticket, err := lot.park(slot.NewRoadVehicle(slot.SCOOTER), time.Now().Add(-time.Minute*(3*60+40)))
-	if err == nil {
-		receipt, _ := lot.UnPark(ticket)
-		if receipt.GetCost() != 30 {
-			t.Errorf(message)
-		}
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot(StadiumParkingLotConfig(), WithClock(clock))
+	if receipt := stay(t, plot, clock, slot.SCOOTER, time.Minute*(3*60+40)); receipt.GetCost() != usd(30) {
+		t.Errorf(message)
 	}
-
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.SCOOTER), time.Now().Add(-time.Minute*(60*14+59))) // 11 hr 30 min
-	if err == nil {
-		receipt, _ := lot.UnPark(ticket)
-		if receipt.GetCost() != 390 {
-			t.Errorf(message)
-		}
+	if receipt := stay(t, plot, clock, slot.SCOOTER, time.Minute*(60*14+59)); receipt.GetCost() != usd(390) {
+		t.Errorf(message)
 	}
-
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.SUV), time.Now().Add(-time.Minute*(60*11+30))) // 11 hr 30 min
-	if err == nil {
-		receipt, _ := lot.UnPark(ticket)
-		if receipt.GetCost() != 180 {
-			t.Errorf(message)
-		}
+	if receipt := stay(t, plot, clock, slot.SUV, time.Minute*(60*11+30)); receipt.GetCost() != usd(180) {
+		t.Errorf(message)
 	}
-
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.SUV), time.Now().Add(-time.Minute*(60*13+5)))
-	if err == nil {
-		receipt, _ := lot.UnPark(ticket)
-		if receipt.GetCost() != 580 {
-			t.Errorf(message)
-		}
+	if receipt := stay(t, plot, clock, slot.SUV, time.Minute*(60*13+5)); receipt.GetCost() != usd(580) {
+		t.Errorf(message)
 	}
 
 	// NO Space available - TRUCK not supported
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.TRUCK), time.Now().Add(-time.Hour*13))
-	if err == nil {
+	if _, err := plot.Park(slot.NewRoadVehicle(slot.TRUCK)); err == nil {
 		t.Errorf(message)
 	}
 }
@@ -238,17 +212,17 @@ func getAirportTarrif() map[int]tariff.Tariff {
 	tariffs := make(map[int]tariff.Tariff)
 
 	scooterTariff := tariff.NewSingleTariffMatcher()
-	scooterTariff.Append(tariff.NewHourInterval(0, tariff.NewTimeConstraint(tariff.HrtoMinutes(0), tariff.HrtoMinutes(1))))
-	scooterTariff.Append(tariff.NewHourInterval(40, tariff.NewTimeConstraint(tariff.HrtoMinutes(1), tariff.HrtoMinutes(8))))
-	scooterTariff.Append(tariff.NewHourInterval(60, tariff.NewTimeConstraint(tariff.HrtoMinutes(8), tariff.HrtoMinutes(24))))
-	scooterTariff.Append(tariff.NewEveryDay(80, tariff.NewTimeConstraint(tariff.DaytoMinutes(0), math.MaxFloat64)))
+	scooterTariff.Append(tariff.NewHourInterval(usd(0), tariff.NewTimeConstraint(tariff.HrtoMinutes(0), tariff.HrtoMinutes(1))))
+	scooterTariff.Append(tariff.NewHourInterval(usd(40), tariff.NewTimeConstraint(tariff.HrtoMinutes(1), tariff.HrtoMinutes(8))))
+	scooterTariff.Append(tariff.NewHourInterval(usd(60), tariff.NewTimeConstraint(tariff.HrtoMinutes(8), tariff.HrtoMinutes(24))))
+	scooterTariff.Append(tariff.NewEveryDay(usd(80), tariff.NewTimeConstraint(tariff.DaytoMinutes(0), math.MaxFloat64)))
 
 	tariffs[slot.SCOOTER] = scooterTariff
 
 	carTariff := tariff.NewSingleTariffMatcher()
-	carTariff.Append(tariff.NewHourInterval(60, tariff.NewTimeConstraint(tariff.HrtoMinutes(0), tariff.HrtoMinutes(12))))
-	carTariff.Append(tariff.NewHourInterval(80, tariff.NewTimeConstraint(tariff.HrtoMinutes(12), tariff.HrtoMinutes(24))))
-	carTariff.Append(tariff.NewEveryDay(100, tariff.NewTimeConstraint(tariff.DaytoMinutes(0), math.MaxFloat64)))
+	carTariff.Append(tariff.NewHourInterval(usd(60), tariff.NewTimeConstraint(tariff.HrtoMinutes(0), tariff.HrtoMinutes(12))))
+	carTariff.Append(tariff.NewHourInterval(usd(80), tariff.NewTimeConstraint(tariff.HrtoMinutes(12), tariff.HrtoMinutes(24))))
+	carTariff.Append(tariff.NewEveryDay(usd(100), tariff.NewTimeConstraint(tariff.DaytoMinutes(0), math.MaxFloat64)))
 	tariffs[slot.SUV] = carTariff
 
 	return tariffs
@@ -264,54 +238,318 @@ func AirportParkingLotConfig() []*ParkingConfig {
 
 func TestAirportParkingLot(t *testing.T) {
 	message := " ******** Airport parking lot case FAILED ******* "
-	plot := NewParkingLot(AirportParkingLotConfig())
-	lot, _ := plot.(*VehicleParkingLot)
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot(AirportParkingLotConfig(), WithClock(clock))
 
-	ticket, err := lot.park(slot.NewRoadVehicle(slot.SCOOTER), time.Now().Add(-time.Minute*59))
-	if err == nil {
-		receipt, _ := lot.UnPark(ticket)
-		if receipt.GetCost() != 0 {
-			t.Errorf(message)
-		}
+	if receipt := stay(t, plot, clock, slot.SCOOTER, time.Minute*59); receipt.GetCost() != usd(0) {
+		t.Errorf(message)
+	}
+	if receipt := stay(t, plot, clock, slot.SCOOTER, time.Minute*(14*60+59)); receipt.GetCost() != usd(60) {
+		t.Errorf(message)
+	}
+	if receipt := stay(t, plot, clock, slot.SCOOTER, time.Hour*(24+12)); receipt.GetCost() != usd(160) {
+		t.Errorf(message)
+	}
+	if receipt := stay(t, plot, clock, slot.SUV, time.Minute*50); receipt.GetCost() != usd(60) {
+		t.Errorf(message)
+	}
+	if receipt := stay(t, plot, clock, slot.SUV, time.Minute*(60*23+59)); receipt.GetCost() != usd(80) {
+		t.Errorf(message)
+	}
+	if receipt := stay(t, plot, clock, slot.SUV, time.Hour*(24*3+1)); receipt.GetCost() != usd(400) {
+		t.Errorf(message)
 	}
+}
 
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.SCOOTER), time.Now().Add(-time.Minute*(14*60+59)))
-	if err == nil {
-		receipt, _ := lot.UnPark(ticket)
-		if receipt.GetCost() != 60 {
-			t.Errorf(message)
+// example 5 : concurrent gates, run with -race
+func TestConcurrentParkUnPark(t *testing.T) {
+	plot := NewParkingLot(MallParkingLotConfig())
+	gates := 500
+	vehicleTypes := []int{slot.SCOOTER, slot.SUV, slot.TRUCK}
+
+	var wg sync.WaitGroup
+	tickets := make(chan slot.Ticket, gates*len(vehicleTypes))
+	for i := 0; i < gates; i++ {
+		for _, vehicleType := range vehicleTypes {
+			wg.Add(1)
+			go func(vehicleType int) {
+				defer wg.Done()
+				ticket, err := plot.Park(slot.NewRoadVehicle(vehicleType))
+				if err == nil {
+					tickets <- ticket
+				}
+			}(vehicleType)
 		}
 	}
-
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.SCOOTER), time.Now().Add(-time.Hour*(24+12)))
-	if err == nil {
-		receipt, _ := lot.UnPark(ticket)
-		if receipt.GetCost() != 160 {
-			t.Errorf(message)
+	wg.Wait()
+	close(tickets)
+
+	ticketNumbers := make(map[int]bool)
+	slotNumbers := make(map[int]map[int]bool)
+	var issued []slot.Ticket
+	for ticket := range tickets {
+		if ticketNumbers[ticket.GetTicketNumber()] {
+			t.Errorf("duplicate ticket number %d", ticket.GetTicketNumber())
+		}
+		ticketNumbers[ticket.GetTicketNumber()] = true
+		if slotNumbers[ticket.GetVehicleType()] == nil {
+			slotNumbers[ticket.GetVehicleType()] = make(map[int]bool)
 		}
+		if slotNumbers[ticket.GetVehicleType()][ticket.GetNumber()] {
+			t.Errorf("slot %d given to two vehicles", ticket.GetNumber())
+		}
+		slotNumbers[ticket.GetVehicleType()][ticket.GetNumber()] = true
+		issued = append(issued, ticket)
+	}
+	// mall capacity 100 + 80 + 100
+	if len(issued) != 280 {
+		t.Errorf("expected 280 tickets, got %d", len(issued))
 	}
 
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.SUV), time.Now().Add(-time.Minute*50))
-	if err == nil {
-		receipt, _ := lot.UnPark(ticket)
-		if receipt.GetCost() != 60 {
-			t.Errorf(message)
+	receipts := make(chan slot.Receipt, len(issued))
+	for _, ticket := range issued {
+		wg.Add(1)
+		go func(ticket slot.Ticket) {
+			defer wg.Done()
+			receipt, err := plot.UnPark(ticket)
+			if err != nil {
+				t.Errorf("unpark failed %v", err)
+				return
+			}
+			receipts <- receipt
+		}(ticket)
+	}
+	wg.Wait()
+	close(receipts)
+
+	receiptNumbers := make(map[int]bool)
+	for receipt := range receipts {
+		if receiptNumbers[receipt.GetReceiptNumber()] {
+			t.Errorf("duplicate receipt number %d", receipt.GetReceiptNumber())
 		}
+		receiptNumbers[receipt.GetReceiptNumber()] = true
+	}
+	if len(receiptNumbers) != len(issued) {
+		t.Errorf("expected %d receipts, got %d", len(issued), len(receiptNumbers))
 	}
 
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.SUV), time.Now().Add(-time.Minute*(60*23+59)))
-	if err == nil {
-		receipt, _ := lot.UnPark(ticket)
-		if receipt.GetCost() != 80 {
-			t.Errorf(message)
+	// all slots free again
+	lot, _ := plot.(*VehicleParkingLot)
+	for vehicleType, slots := range lot.slots {
+		for _, v := range slots {
+			if !v.IsFree() {
+				t.Errorf("slot %d of vehicle type %d not released", v.GetNumber(), vehicleType)
+			}
 		}
 	}
+}
+
+func TestConcurrentParkSingleSlot(t *testing.T) {
+	plot := NewParkingLot(SmallParkingLotConfig())
+	var wg sync.WaitGroup
+	var parked int64
+	for i := 0; i < 300; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticket, err := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+			if err != nil {
+				return
+			}
+			atomic.AddInt64(&parked, 1)
+			if _, err := plot.UnPark(ticket); err != nil {
+				t.Errorf("unpark failed %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if parked == 0 {
+		t.Errorf("no vehicle parked")
+	}
+}
+
+// example 6 : deterministic billing with fake clock
+func parkFor(t *testing.T, plot Parkinglot, clock *FakeClock, vehicleType int, inTime time.Time, outTime time.Time) money.Money {
+	clock.Set(inTime)
+	ticket, err := plot.Park(slot.NewRoadVehicle(vehicleType))
+	if err != nil {
+		t.Fatalf("park failed %v", err)
+	}
+	clock.Set(outTime)
+	receipt, err := plot.UnPark(ticket)
+	if err != nil {
+		t.Fatalf("unpark failed %v", err)
+	}
+	return receipt.GetCost()
+}
+
+func TestClockMultiDayStay(t *testing.T) {
+	clock := NewFakeClock(time.Time{})
+	plot := NewParkingLot(AirportParkingLotConfig(), WithClock(clock))
+	inTime := time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	// 3 days 1 hour : 4 days * 100
+	if cost := parkFor(t, plot, clock, slot.SUV, inTime, inTime.Add(time.Hour*(24*3+1))); cost != usd(400) {
+		t.Errorf("multi day stay failed %v", cost)
+	}
+	// exactly one day falls into the per day model
+	if cost := parkFor(t, plot, clock, slot.SCOOTER, inTime, inTime.Add(time.Hour*24)); cost != usd(80) {
+		t.Errorf("one day stay failed %v", cost)
+	}
+}
+
+func TestClockMidnightCrossing(t *testing.T) {
+	clock := NewFakeClock(time.Time{})
+	plot := NewParkingLot(StadiumParkingLotConfig(), WithClock(clock))
+
+	// 23:00 - 03:00 : [0, 4) hours
+	inTime := time.Date(2021, 12, 31, 23, 0, 0, 0, time.UTC)
+	if cost := parkFor(t, plot, clock, slot.SCOOTER, inTime, inTime.Add(time.Hour*4-time.Minute)); cost != usd(30) {
+		t.Errorf("midnight crossing failed %v", cost)
+	}
+	// 23:00 - 04:00 : [4, 12) hours, 30 + 60
+	if cost := parkFor(t, plot, clock, slot.SCOOTER, inTime, inTime.Add(time.Hour*5)); cost != usd(90) {
+		t.Errorf("midnight crossing failed %v", cost)
+	}
+}
+
+func TestClockDaylightSaving(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone not available %v", err)
+	}
+	clock := NewFakeClock(time.Time{})
+	plot := NewParkingLot(MallParkingLotConfig(), WithClock(clock))
+
+	// fall back : 22:00 - 06:00 wall clock is 9 hours
+	inTime := time.Date(2021, 11, 6, 22, 0, 0, 0, location)
+	outTime := time.Date(2021, 11, 7, 6, 0, 0, 0, location)
+	if cost := parkFor(t, plot, clock, slot.SUV, inTime, outTime); cost != usd(180) {
+		t.Errorf("daylight saving fall back failed %v", cost)
+	}
+
+	// spring forward : 22:00 - 06:00 wall clock is 7 hours
+	inTime = time.Date(2021, 3, 13, 22, 0, 0, 0, location)
+	outTime = time.Date(2021, 3, 14, 6, 0, 0, 0, location)
+	if cost := parkFor(t, plot, clock, slot.SUV, inTime, outTime); cost != usd(140) {
+		t.Errorf("daylight saving spring forward failed %v", cost)
+	}
+}
 
-	ticket, err = lot.park(slot.NewRoadVehicle(slot.SUV), time.Now().Add(-time.Hour*(24*3+1)))
-	if err == nil {
-		receipt, _ := lot.UnPark(ticket)
-		if receipt.GetCost() != 400 {
-			t.Errorf(message)
+func TestReceiptLineItems(t *testing.T) {
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot(StadiumParkingLotConfig(), WithClock(clock))
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SUV))
+	clock.Advance(time.Hour*13 + time.Minute*5)
+	receipt, _ := plot.UnPark(ticket)
+
+	// 60 + 120 + 2 hours * 200
+	lines := receipt.GetLines()
+	if len(lines) != 3 || lines[2].Quantity != 2 || lines[2].Amount != usd(400) || lines[2].Description != "EveryHourInInterval [12h0m0s, inf)" {
+		t.Errorf("unexpected receipt lines %v", lines)
+	}
+	var sum money.Money
+	for _, line := range lines {
+		sum = sum.Add(line.Amount)
+	}
+	if sum != receipt.GetCost() {
+		t.Errorf("receipt lines do not add up to the cost")
+	}
+}
+
+func TestReceiptCharges(t *testing.T) {
+	vat, err := charges.NewTax("VAT", "20")
+	if err != nil {
+		t.Fatalf("tax failed %v", err)
+	}
+	config := NewParkingConfig(slot.SUV, 2, euroTariff(500))
+	err = config.SetCharges(charges.NewPipeline(
+		charges.NewSurcharge("Facility fee", money.New(200, "EUR")), vat, charges.NewRounding(money.New(100, "EUR"), money.RoundHalfUp)))
+	if err != nil {
+		t.Fatalf("charges failed %v", err)
+	}
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot([]*ParkingConfig{config}, WithClock(clock))
+	// 3h at 5.00 = 15.00, net 17.00, tax 3.40, gross 20.40 rounds to 20.00
+	receipt := stay(t, plot, clock, slot.SUV, time.Hour*3)
+	if receipt.GetNet() != money.New(1700, "EUR") || receipt.GetTax() != money.New(340, "EUR") || receipt.GetCost() != money.New(2000, "EUR") {
+		t.Errorf("unexpected totals net %v tax %v gross %v", receipt.GetNet(), receipt.GetTax(), receipt.GetCost())
+	}
+	kinds := []string{slot.LineTariff, slot.LineSurcharge, slot.LineTax, slot.LineRounding}
+	lines := receipt.GetLines()
+	if len(lines) != len(kinds) {
+		t.Fatalf("unexpected lines %v", lines)
+	}
+	for i, kind := range kinds {
+		if lines[i].Kind != kind {
+			t.Errorf("line %d kind %s, expected %s", i, lines[i].Kind, kind)
 		}
 	}
+	report, _ := plot.Report()
+	if report.Total != money.New(2000, "EUR") {
+		t.Errorf("revenue must book the gross %v", report.Total)
+	}
+}
+
+func TestChargesCurrency(t *testing.T) {
+	config := NewParkingConfig(slot.SUV, 1, euroTariff(500))
+	var currencyError *charges.CurrencyError
+	err := config.SetCharges(charges.NewPipeline(charges.NewSurcharge("Facility fee", usd(2))))
+	if !errors.Is(err, money.ErrCurrencyMismatch) || !errors.As(err, &currencyError) || currencyError.Step != "Facility fee" {
+		t.Errorf("expected a currency error, got %v", err)
+	}
+	err = config.SetCharges(charges.NewPipeline(charges.NewRounding(money.New(5, "CHF"), money.RoundHalfUp)))
+	if !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("expected a currency error, got %v", err)
+	}
+	if config.GetCharges() != nil {
+		t.Errorf("rejected charges must not be set")
+	}
+	// the lot bills without charges rather than failing at exit
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot([]*ParkingConfig{config}, WithClock(clock))
+	if receipt := stay(t, plot, clock, slot.SUV, time.Hour); receipt.GetCost() != money.New(500, "EUR") {
+		t.Errorf("unexpected receipt %v", receipt)
+	}
+}
+
+func TestNamedParkingConfig(t *testing.T) {
+	bus, err := slot.RegisterVehicleType("Bus", slot.SizeLarge, nil)
+	if err != nil {
+		t.Fatalf("register failed %v", err)
+	}
+	t.Cleanup(func() { slot.UnregisterVehicleType(bus) })
+	config, err := NewNamedParkingConfig("bus", 1, getMallTariff()[slot.TRUCK])
+	if err != nil {
+		t.Fatalf("config failed %v", err)
+	}
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot([]*ParkingConfig{config}, WithClock(clock))
+	ticket, err := plot.Park(slot.NewRoadVehicle(bus))
+	if err != nil || ticket.GetVehicleType() != bus {
+		t.Fatalf("park failed %v", err)
+	}
+	clock.Advance(time.Hour)
+	if receipt, err := plot.UnPark(ticket); err != nil || receipt.GetCost() != usd(50) {
+		t.Errorf("unpark failed %v %v", receipt, err)
+	}
+	if _, err := NewNamedParkingConfig("Tram", 1, getMallTariff()[slot.TRUCK]); !errors.Is(err, ErrUnknownVehicle) {
+		t.Errorf("expected ErrUnknownVehicle, got %v", err)
+	}
+}
+
+func TestTimeOfDayZeroStay(t *testing.T) {
+	model, err := tariff.NewTimeOfDay(usd(1), tariff.NewBand("Day", 8*time.Hour, 18*time.Hour, usd(5)))
+	if err != nil {
+		t.Fatalf("time of day failed %v", err)
+	}
+	timeOfDay := tariff.NewSingleTariffMatcher()
+	timeOfDay.Append(model)
+	clock := NewFakeClock(time.Date(2021, 1, 4, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot([]*ParkingConfig{NewParkingConfig(slot.SCOOTER, 1, timeOfDay)}, WithClock(clock))
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	receipt, err := plot.UnPark(ticket)
+	if err != nil || receipt.GetCost() != usd(0) {
+		t.Errorf("leaving at the in-time must be free, got %v %v", receipt, err)
+	}
 }
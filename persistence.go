@@ -0,0 +1,189 @@
+package parking
+
+import (
+	"fmt"
+	"github.com/hbkkanna/parking/money"
+	"sort"
+	"sync/atomic"
+)
+
+// OpenParkingLot creates the parking lot and rebuilds slot occupancy, issued tickets and
+// ticket and receipt numbers from the store. Every later operation is journaled to the store
+// before the lot state changes.
+func OpenParkingLot(configs []*ParkingConfig, store Store, options ...Option) (Parkinglot, error) {
+	for _, config := range configs {
+		if err := config.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	plot := NewParkingLot(configs, options...)
+	parkingLot := plot.(*VehicleParkingLot)
+	snapshot, records, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if snapshot != nil {
+		if err := parkingLot.restoreSnapshot(snapshot); err != nil {
+			return nil, err
+		}
+	}
+	for _, record := range records {
+		if record.Sequence <= parkingLot.sequence {
+			continue
+		}
+		if err := parkingLot.replay(record); err != nil {
+			return nil, err
+		}
+	}
+	parkingLot.store = store
+	return parkingLot, nil
+}
+
+// Checkpoint saves a snapshot of the lot to the store, the journal restarts after it
+func (parkingLot *VehicleParkingLot) Checkpoint() error {
+	if parkingLot.store == nil {
+		return nil
+	}
+	unlock := parkingLot.lockAll()
+	defer unlock()
+	snapshot := Snapshot{
+		Sequence:   atomic.LoadInt64(&parkingLot.sequence),
+		TicketCnt:  atomic.LoadInt64(&parkingLot.ticketCnt),
+		ReceiptCnt: atomic.LoadInt64(&parkingLot.receiptCnt),
+	}
+	for _, record := range parkingLot.tickets.list() {
+		state := TicketState{
+			TicketNumber: record.ticketNumber,
+			VehicleType:  record.vehicleType,
+			SlotNumber:   record.slotNumber,
+			Status:       record.status,
+			Plate:        record.plate,
+		}
+		if record.tariffType != record.vehicleType {
+			tariffType := record.tariffType
+			state.TariffType = &tariffType
+		}
+		if record.status == TicketIssued {
+			vehicleSlot, err := parkingLot.getSlot(record.vehicleType, record.slotNumber)
+			if err != nil {
+				return err
+			}
+			state.InTime = vehicleSlot.GetInTime()
+		}
+		snapshot.Tickets = append(snapshot.Tickets, state)
+	}
+	for vehicleType, takings := range parkingLot.revenue {
+		if takings.receipts == 0 {
+			continue
+		}
+		if snapshot.Revenue == nil {
+			snapshot.Revenue = make(map[int]money.Money)
+			snapshot.Receipts = make(map[int]int)
+		}
+		snapshot.Revenue[vehicleType] = takings.total
+		snapshot.Receipts[vehicleType] = takings.receipts
+	}
+	return parkingLot.store.Save(snapshot)
+}
+
+// journal writes the record ahead of the state change, the lot works in memory without a store.
+// Gates of different vehicle types journal concurrently, the sequence is taken and appended under
+// one lock so the journal is written in sequence order.
+func (parkingLot *VehicleParkingLot) journal(record Record) error {
+	if parkingLot.store == nil {
+		return nil
+	}
+	parkingLot.journalMutex.Lock()
+	defer parkingLot.journalMutex.Unlock()
+	record.Sequence = atomic.AddInt64(&parkingLot.sequence, 1)
+	return parkingLot.store.Append(record)
+}
+
+// lockAll acquires the locks of every vehicle type in a fixed order
+func (parkingLot *VehicleParkingLot) lockAll() func() {
+	var vehicleTypes []int
+	for vehicleType := range parkingLot.locks {
+		vehicleTypes = append(vehicleTypes, vehicleType)
+	}
+	sort.Ints(vehicleTypes)
+	var unlocks []func()
+	for _, vehicleType := range vehicleTypes {
+		unlocks = append(unlocks, parkingLot.lock(vehicleType))
+	}
+	return func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+}
+
+func (parkingLot *VehicleParkingLot) restoreSnapshot(snapshot *Snapshot) error {
+	parkingLot.sequence = snapshot.Sequence
+	parkingLot.ticketCnt = snapshot.TicketCnt
+	parkingLot.receiptCnt = snapshot.ReceiptCnt
+	for vehicleType, total := range snapshot.Revenue {
+		if takings, ok := parkingLot.revenue[vehicleType]; ok {
+			takings.total = total
+			takings.receipts = snapshot.Receipts[vehicleType]
+		}
+	}
+	for _, state := range snapshot.Tickets {
+		vehicleSlot, err := parkingLot.getSlot(state.VehicleType, state.SlotNumber)
+		if err != nil {
+			return fmt.Errorf("restore ticket %d: %w", state.TicketNumber, err)
+		}
+		parkingLot.tickets.issue(state.TicketNumber, state.VehicleType, state.SlotNumber, state.Plate,
+			getTariffType(state.TariffType, state.VehicleType))
+		if state.Status == TicketIssued {
+			vehicleSlot.SetInTime(state.InTime)
+			vehicleSlot.SetPlate(state.Plate)
+			parkingLot.allocators[state.VehicleType].Take(state.SlotNumber)
+		} else {
+			parkingLot.tickets.close(state.TicketNumber, state.Status)
+		}
+	}
+	return nil
+}
+
+func (parkingLot *VehicleParkingLot) replay(record Record) error {
+	vehicleSlot, err := parkingLot.getSlot(record.VehicleType, record.SlotNumber)
+	if err != nil {
+		return fmt.Errorf("replay record %d: %w", record.Sequence, err)
+	}
+	switch record.Op {
+	case OpPark:
+		vehicleSlot.SetInTime(record.Time)
+		vehicleSlot.SetPlate(record.Plate)
+		parkingLot.allocators[record.VehicleType].Take(record.SlotNumber)
+		parkingLot.tickets.issue(record.TicketNumber, record.VehicleType, record.SlotNumber, record.Plate,
+			getTariffType(record.TariffType, record.VehicleType))
+	case OpUnPark, OpVoid, OpLostTicket:
+		status := TicketRedeemed
+		if record.Op != OpUnPark {
+			status = TicketVoided
+		}
+		parkingLot.tickets.close(record.TicketNumber, status)
+		if record.Cost != nil {
+			parkingLot.addRevenue(parkingLot.tickets.tariffType(record.TicketNumber, record.VehicleType), *record.Cost)
+		}
+		parkingLot.releaseSlot(vehicleSlot)
+	default:
+		return fmt.Errorf("replay record %d: unknown operation %q", record.Sequence, record.Op)
+	}
+	parkingLot.sequence = record.Sequence
+	if int64(record.TicketNumber) > parkingLot.ticketCnt {
+		parkingLot.ticketCnt = int64(record.TicketNumber)
+	}
+	if int64(record.ReceiptNumber) > parkingLot.receiptCnt {
+		parkingLot.receiptCnt = int64(record.ReceiptNumber)
+	}
+	return nil
+}
+
+// getTariffType returns the journaled tariff type, the slot type when none was journaled
+func getTariffType(tariffType *int, slotType int) int {
+	if tariffType != nil {
+		return *tariffType
+	}
+	return slotType
+}
@@ -0,0 +1,249 @@
+package parking
+
+import (
+	"errors"
+	"github.com/hbkkanna/parking/slot"
+	"sync"
+	"testing"
+	"time"
+)
+
+func openLot(t *testing.T, dir string, clock Clock) Parkinglot {
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("open store failed %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	plot, err := OpenParkingLot(MallParkingLotConfig(), store, WithClock(clock))
+	if err != nil {
+		t.Fatalf("open parking lot failed %v", err)
+	}
+	return plot
+}
+
+func TestRecoverAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := openLot(t, dir, clock)
+
+	scooter, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	suv, _ := plot.Park(slot.NewRoadVehicle(slot.SUV))
+	truck, _ := plot.Park(slot.NewRoadVehicle(slot.TRUCK))
+	clock.Advance(time.Hour)
+	plot.UnPark(scooter)
+	plot.VoidTicket(truck)
+
+	// process restarts, the old lot is dropped without any shutdown
+	recovered := openLot(t, dir, clock)
+	lot := recovered.(*VehicleParkingLot)
+	if !lot.slots[slot.SCOOTER][0].IsFree() || lot.slots[slot.SUV][0].IsFree() || !lot.slots[slot.TRUCK][0].IsFree() {
+		t.Errorf("occupancy not recovered")
+	}
+	if _, err := recovered.UnPark(scooter); !errors.Is(err, ErrTicketAlreadyUsed) {
+		t.Errorf("expected ErrTicketAlreadyUsed, got %v", err)
+	}
+	if _, err := recovered.UnPark(truck); !errors.Is(err, ErrTicketVoided) {
+		t.Errorf("expected ErrTicketVoided, got %v", err)
+	}
+
+	next, _ := recovered.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	if next.GetTicketNumber() != 4 {
+		t.Errorf("ticket number not continued, got %d", next.GetTicketNumber())
+	}
+	clock.Advance(time.Hour * 2)
+	receipt, err := recovered.UnPark(suv)
+	if err != nil || receipt.GetReceiptNumber() != 2 || receipt.GetCost() != usd(60) {
+		t.Errorf("unpark of recovered ticket failed %v", err)
+	}
+	if report, _ := recovered.Report(); report.Total != usd(70) || report.Receipts != 2 {
+		t.Errorf("revenue not recovered %+v", report)
+	}
+}
+
+func TestRecoverFromSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := openLot(t, dir, clock)
+
+	first, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	second, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	clock.Advance(time.Hour)
+	plot.UnPark(first)
+	if err := plot.Checkpoint(); err != nil {
+		t.Fatalf("checkpoint failed %v", err)
+	}
+	third, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+
+	recovered := openLot(t, dir, clock)
+	if _, err := recovered.UnPark(first); !errors.Is(err, ErrTicketAlreadyUsed) {
+		t.Errorf("expected ErrTicketAlreadyUsed, got %v", err)
+	}
+	clock.Advance(time.Hour)
+	receipt, err := recovered.UnPark(second)
+	if err != nil || receipt.GetCost() != usd(20) || receipt.GetReceiptNumber() != 2 {
+		t.Errorf("unpark from snapshot failed %v", err)
+	}
+	receipt, err = recovered.UnPark(third)
+	if err != nil || receipt.GetCost() != usd(10) || receipt.GetReceiptNumber() != 3 {
+		t.Errorf("unpark from journal after snapshot failed %v", err)
+	}
+	// 10 before the snapshot, 20 and 10 after the restart
+	if report, _ := recovered.Report(); report.Total != usd(40) || report.Receipts != 3 {
+		t.Errorf("revenue not recovered from snapshot %+v", report)
+	}
+}
+
+func TestRecoverPlates(t *testing.T) {
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := openLot(t, dir, clock)
+	plot.Park(slot.NewRegisteredVehicle(slot.SUV, "AB12CD", nil))
+	if err := plot.Checkpoint(); err != nil {
+		t.Fatalf("checkpoint failed %v", err)
+	}
+	plot.Park(slot.NewRegisteredVehicle(slot.SCOOTER, "XY99", nil))
+
+	// from the snapshot and from the journal
+	recovered := openLot(t, dir, clock)
+	for _, plate := range []string{"AB12CD", "XY99"} {
+		ticket, err := recovered.FindByPlate(plate)
+		if err != nil || ticket.GetPlate() != plate {
+			t.Errorf("plate %s not recovered %v", plate, err)
+		}
+		if _, err := recovered.Park(slot.NewRegisteredVehicle(slot.TRUCK, plate, nil)); !errors.Is(err, ErrPlateParked) {
+			t.Errorf("expected ErrPlateParked, got %v", err)
+		}
+	}
+}
+
+func TestRecoverLostTicketExit(t *testing.T) {
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := openLot(t, dir, clock)
+	ticket, _ := plot.Park(slot.NewRegisteredVehicle(slot.SUV, "AB12CD", nil))
+	clock.Advance(time.Hour)
+	if _, err := plot.LostTicketExit(LostTicket{Plate: "AB12CD"}); err != nil {
+		t.Fatalf("lost ticket exit failed %v", err)
+	}
+
+	recovered := openLot(t, dir, clock)
+	if _, err := recovered.UnPark(ticket); !errors.Is(err, ErrTicketVoided) {
+		t.Errorf("expected ErrTicketVoided, got %v", err)
+	}
+	if report, _ := recovered.Report(); report.Total != usd(20) || report.Receipts != 1 {
+		t.Errorf("revenue not recovered %+v", report)
+	}
+}
+
+func TestRecoverOverflow(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("open store failed %v", err)
+	}
+	defer store.Close()
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	configs := func() []*ParkingConfig {
+		scooter := NewParkingConfig(slot.SCOOTER, 1, getMallTariff()[slot.SCOOTER])
+		scooter.AllowOverflow(Overflow{SlotType: slot.SUV})
+		return []*ParkingConfig{scooter, NewParkingConfig(slot.SUV, 2, getMallTariff()[slot.SUV])}
+	}
+	plot, _ := OpenParkingLot(configs(), store, WithClock(clock))
+	plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+
+	recovered, err := OpenParkingLot(configs(), store, WithClock(clock))
+	if err != nil {
+		t.Fatalf("recover failed %v", err)
+	}
+	clock.Advance(time.Hour)
+	receipt, err := recovered.UnPark(ticket)
+	if err != nil || receipt.GetCost() != usd(10) {
+		t.Errorf("overflow must be billed with the scooter tariff %v %v", receipt, err)
+	}
+}
+
+// failingStore : journal write fails, as if the disk went away mid operation
+type failingStore struct {
+	Store
+}
+
+func (store *failingStore) Append(record Record) error {
+	return errors.New("disk failure")
+}
+
+func TestJournalFailureLeavesLotUnchanged(t *testing.T) {
+	plot, err := OpenParkingLot(SmallParkingLotConfig(), &failingStore{Store: memoryStore{}})
+	if err != nil {
+		t.Fatalf("open parking lot failed %v", err)
+	}
+	if _, err := plot.Park(slot.NewRoadVehicle(slot.SCOOTER)); err == nil {
+		t.Errorf("expected journal error")
+	}
+	lot := plot.(*VehicleParkingLot)
+	if !lot.slots[slot.SCOOTER][0].IsFree() || lot.allocators[slot.SCOOTER].Free() != len(lot.slots[slot.SCOOTER]) {
+		t.Errorf("slot taken without journal record")
+	}
+}
+
+type memoryStore struct{}
+
+func (store memoryStore) Append(record Record) error         { return nil }
+func (store memoryStore) Save(snapshot Snapshot) error       { return nil }
+func (store memoryStore) Load() (*Snapshot, []Record, error) { return nil, nil, nil }
+
+// slowStore : scooter records are appended late, as if their gate was descheduled mid journal
+type slowStore struct {
+	Store
+}
+
+func (store slowStore) Append(record Record) error {
+	if record.VehicleType == slot.SCOOTER {
+		time.Sleep(time.Millisecond)
+	}
+	return store.Store.Append(record)
+}
+
+func TestRecoverConcurrentGates(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("open store failed %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	plot, err := OpenParkingLot(MallParkingLotConfig(), slowStore{Store: store})
+	if err != nil {
+		t.Fatalf("open parking lot failed %v", err)
+	}
+	var wg sync.WaitGroup
+	for _, vehicleType := range []int{slot.SCOOTER, slot.SUV} {
+		wg.Add(1)
+		go func(vehicleType int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				if _, err := plot.Park(slot.NewRoadVehicle(vehicleType)); err != nil {
+					t.Errorf("park failed %v", err)
+				}
+			}
+		}(vehicleType)
+	}
+	wg.Wait()
+
+	_, records, err := store.Load()
+	if err != nil {
+		t.Fatalf("load failed %v", err)
+	}
+	for i, record := range records {
+		if record.Sequence != int64(i+1) {
+			t.Fatalf("journal out of sequence order at %d: %d", i, record.Sequence)
+		}
+	}
+	recovered := openLot(t, dir, NewRealClock()).(*VehicleParkingLot)
+	for _, vehicleType := range []int{slot.SCOOTER, slot.SUV} {
+		for i := 0; i < 10; i++ {
+			if recovered.slots[vehicleType][i].IsFree() {
+				t.Errorf("slot %d of vehicle type %d not recovered", i, vehicleType)
+			}
+		}
+	}
+}
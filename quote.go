@@ -0,0 +1,70 @@
+package parking
+
+import (
+	"fmt"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"strings"
+	"time"
+)
+
+// Quote : price preview of a stay, priced like UnPark with the tariff and charges of the vehicle type
+type Quote struct {
+	VehicleType int
+	InTime      time.Time
+	OutTime     time.Time
+	Totals      slot.Totals
+	Lines       []slot.ReceiptLine
+}
+
+// GetCost returns the gross amount to pay
+func (quote Quote) GetCost() money.Money {
+	return quote.Totals.Gross
+}
+
+func (quote Quote) String() string {
+	var lines strings.Builder
+	for _, line := range quote.Lines {
+		lines.WriteString("\n    " + line.String())
+	}
+	return fmt.Sprintf("Parking Quote: \n  Entry Date-Time: %v \n  Exit Date-Time: %v \n  Cost: %s%s",
+		quote.InTime, quote.OutTime, quote.Totals.Gross.Format(), lines.String())
+}
+
+// Quote prices a stay of the vehicle type between the times, no slot or ticket is touched
+func (parkingLot *VehicleParkingLot) Quote(vehicleType int, inTime time.Time, outTime time.Time) (Quote, error) {
+	if _, ok := parkingLot.tariff[vehicleType]; !ok {
+		return Quote{}, fmt.Errorf("%w %d", ErrUnknownVehicle, vehicleType)
+	}
+	return parkingLot.quote(vehicleType, inTime, outTime)
+}
+
+// QuoteTicket prices the stay of an issued ticket leaving at the time, e.g. the clock time for
+// "leave now", the ticket stays open and its slot is not released
+func (parkingLot *VehicleParkingLot) QuoteTicket(ticket slot.Ticket, at time.Time) (Quote, error) {
+	unlock := parkingLot.lock(ticket.GetVehicleType())
+	defer unlock()
+	vehicleSlot, err := parkingLot.getTicketSlot(ticket)
+	if err != nil {
+		return Quote{}, err
+	}
+	// priced from the lot's own slot, like UnPark
+	return parkingLot.quote(parkingLot.tickets.tariffType(ticket.GetTicketNumber(), vehicleSlot.GetVehicleType()),
+		vehicleSlot.GetInTime(), at)
+}
+
+func (parkingLot *VehicleParkingLot) quote(vehicleType int, inTime time.Time, outTime time.Time) (Quote, error) {
+	if err := parkingLot.invalid[vehicleType]; err != nil {
+		return Quote{}, err
+	}
+	parkingTime := slot.NewParkingTime()
+	parkingTime.SetInTime(inTime)
+	if err := parkingTime.SetOutTime(outTime); err != nil {
+		return Quote{}, err
+	}
+	totals, lines, err := parkingLot.price(vehicleType, parkingLot.tariff[vehicleType], parkingTime)
+	if err != nil {
+		return Quote{}, err
+	}
+	return Quote{VehicleType: vehicleType, InTime: inTime, OutTime: outTime, Totals: totals, Lines: lines}, nil
+}
@@ -0,0 +1,71 @@
+package parking
+
+import (
+	"errors"
+	"github.com/hbkkanna/parking/charges"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"testing"
+	"time"
+)
+
+func TestQuote(t *testing.T) {
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot(StadiumParkingLotConfig(), WithClock(clock))
+
+	// 60 + 120 + 2 hours * 200, same as the receipt
+	inTime := clock.Now()
+	quote, err := plot.Quote(slot.SUV, inTime, inTime.Add(time.Hour*13+time.Minute*5))
+	if err != nil || quote.GetCost() != usd(580) || len(quote.Lines) != 3 {
+		t.Fatalf("unexpected quote %v %v", quote, err)
+	}
+	if quote.Lines[2].Description != "EveryHourInInterval [12h0m0s, inf)" {
+		t.Errorf("unexpected quote line %v", quote.Lines[2])
+	}
+
+	if _, err := plot.Quote(slot.SUV, inTime, inTime.Add(-time.Hour)); !errors.Is(err, slot.ErrInvalidOutTime) {
+		t.Errorf("expected ErrInvalidOutTime, got %v", err)
+	}
+	if _, err := plot.Quote(42, inTime, inTime.Add(time.Hour)); !errors.Is(err, ErrUnknownVehicle) {
+		t.Errorf("expected ErrUnknownVehicle, got %v", err)
+	}
+	// quoting does not take a slot
+	report, _ := plot.Report()
+	if report.Receipts != 0 {
+		t.Errorf("quote must not be booked %v", report)
+	}
+}
+
+func TestQuoteTicket(t *testing.T) {
+	vat, _ := charges.NewTax("VAT", "20")
+	config := NewParkingConfig(slot.SUV, 1, euroTariff(500))
+	if err := config.SetCharges(charges.NewPipeline(vat)); err != nil {
+		t.Fatalf("charges failed %v", err)
+	}
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot([]*ParkingConfig{config}, WithClock(clock))
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SUV))
+	clock.Advance(time.Hour * 2)
+
+	// leave now and leave at 18:00
+	now, err := plot.QuoteTicket(ticket, clock.Now())
+	if err != nil || now.GetCost() != money.New(1200, "EUR") || now.Totals.Tax != money.New(200, "EUR") {
+		t.Fatalf("unexpected quote %v %v", now, err)
+	}
+	later, err := plot.QuoteTicket(ticket, time.Date(2021, 1, 1, 18, 0, 0, 0, time.UTC))
+	if err != nil || later.GetCost() != money.New(4800, "EUR") {
+		t.Fatalf("unexpected quote %v %v", later, err)
+	}
+
+	// the ticket is still open and the slot taken
+	if _, err := plot.Park(slot.NewRoadVehicle(slot.SUV)); !errors.Is(err, ErrNoSpace) {
+		t.Errorf("quote must not release the slot, got %v", err)
+	}
+	receipt, err := plot.UnPark(ticket)
+	if err != nil || receipt.GetCost() != now.GetCost() {
+		t.Errorf("receipt %v does not match the quote %v", receipt, now)
+	}
+	if _, err := plot.QuoteTicket(ticket, clock.Now()); !errors.Is(err, ErrTicketAlreadyUsed) {
+		t.Errorf("expected ErrTicketAlreadyUsed, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package parking
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hbkkanna/parking/money"
+	"sort"
+	"sync"
+)
+
+var ErrMixedCurrency = errors.New("tariffs of different currencies")
+
+// Report : revenue of the redeemed tickets by vehicle type
+type Report struct {
+	Currency string
+	Total    money.Money
+	Revenue  map[int]money.Money
+	Receipts int
+}
+
+// revenue : takings of one vehicle type. Stays in the slots of another vehicle type can be
+// booked to it, writers hold the mutex, readers hold every vehicle type lock.
+type revenue struct {
+	mutex    sync.Mutex
+	total    money.Money
+	receipts int
+}
+
+// Report sums the revenue of the lot, it is refused with ErrMixedCurrency when the
+// tariffs of the lot are priced in different currencies
+func (parkingLot *VehicleParkingLot) Report() (Report, error) {
+	currencies := parkingLot.getCurrencies()
+	if len(currencies) > 1 {
+		return Report{}, fmt.Errorf("%w: %v, use ConsolidatedReport", ErrMixedCurrency, currencies)
+	}
+	currency := ""
+	if len(currencies) == 1 {
+		currency = currencies[0]
+	}
+	return parkingLot.report(currency, func(value money.Money) (money.Money, error) {
+		return value, nil
+	})
+}
+
+// ConsolidatedReport converts the revenue of every vehicle type into the currency with the provider rates
+func (parkingLot *VehicleParkingLot) ConsolidatedReport(currency string, provider money.RateProvider) (Report, error) {
+	return parkingLot.report(currency, func(value money.Money) (money.Money, error) {
+		return money.Convert(value, currency, provider, money.RoundHalfEven)
+	})
+}
+
+func (parkingLot *VehicleParkingLot) report(currency string, convert func(value money.Money) (money.Money, error)) (Report, error) {
+	unlock := parkingLot.lockAll()
+	defer unlock()
+	report := Report{Currency: currency, Total: money.New(0, currency), Revenue: make(map[int]money.Money)}
+	for vehicleType, takings := range parkingLot.revenue {
+		value, err := convert(takings.total)
+		if err != nil {
+			return Report{}, err
+		}
+		if value.Currency() == "" {
+			value = money.New(0, currency)
+		}
+		report.Revenue[vehicleType] = value
+		report.Total = report.Total.Add(value)
+		report.Receipts += takings.receipts
+	}
+	return report, nil
+}
+
+// getCurrencies returns the distinct currencies of the lot tariffs
+func (parkingLot *VehicleParkingLot) getCurrencies() []string {
+	seen := make(map[string]bool)
+	var currencies []string
+	for _, vehicleTariff := range parkingLot.tariff {
+		currency := vehicleTariff.GetCurrency()
+		if currency != "" && !seen[currency] {
+			seen[currency] = true
+			currencies = append(currencies, currency)
+		}
+	}
+	sort.Strings(currencies)
+	return currencies
+}
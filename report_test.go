@@ -0,0 +1,166 @@
+package parking
+
+import (
+	"errors"
+	"github.com/hbkkanna/parking/charges"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"github.com/hbkkanna/parking/tariff"
+	"testing"
+	"time"
+)
+
+func stay(t *testing.T, plot Parkinglot, clock *FakeClock, vehicleType int, duration time.Duration) slot.Receipt {
+	ticket, err := plot.Park(slot.NewRoadVehicle(vehicleType))
+	if err != nil {
+		t.Fatalf("park failed %v", err)
+	}
+	clock.Advance(duration)
+	receipt, err := plot.UnPark(ticket)
+	if err != nil {
+		t.Fatalf("unpark failed %v", err)
+	}
+	return receipt
+}
+
+func TestReport(t *testing.T) {
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot(MallParkingLotConfig(), WithClock(clock))
+	stay(t, plot, clock, slot.SCOOTER, time.Hour*2)
+	stay(t, plot, clock, slot.SUV, time.Hour*3)
+	stay(t, plot, clock, slot.SUV, time.Hour)
+
+	report, err := plot.Report()
+	if err != nil {
+		t.Fatalf("report failed %v", err)
+	}
+	if report.Currency != "USD" || report.Total != usd(100) || report.Receipts != 3 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if report.Revenue[slot.SCOOTER] != usd(20) || report.Revenue[slot.SUV] != usd(80) || report.Revenue[slot.TRUCK] != usd(0) {
+		t.Errorf("unexpected revenue %v", report.Revenue)
+	}
+}
+
+func euroTariff(price int64) tariff.Tariff {
+	euroTariff := tariff.NewSingleTariffMatcher()
+	euroTariff.Append(tariff.NewEveryHour(money.New(price, "EUR")))
+	return euroTariff
+}
+
+func TestMixedCurrencyReport(t *testing.T) {
+	configs := []*ParkingConfig{
+		NewParkingConfig(slot.SCOOTER, 10, euroTariff(500)),
+		NewParkingConfig(slot.SUV, 10, getMallTariff()[slot.SUV]),
+	}
+	if configs[0].GetCurrency() != "EUR" || configs[1].GetCurrency() != "USD" {
+		t.Errorf("unexpected config currencies")
+	}
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot(configs, WithClock(clock))
+	receipt := stay(t, plot, clock, slot.SCOOTER, time.Hour*2)
+	if receipt.GetCost() != money.New(1000, "EUR") {
+		t.Errorf("unexpected cost %v", receipt.GetCost())
+	}
+	stay(t, plot, clock, slot.SUV, time.Hour)
+
+	if _, err := plot.Report(); !errors.Is(err, ErrMixedCurrency) {
+		t.Errorf("expected ErrMixedCurrency, got %v", err)
+	}
+
+	rates := money.NewStaticRates()
+	rates.Set("EUR", "USD", "1.10")
+	report, err := plot.ConsolidatedReport("USD", rates)
+	if err != nil {
+		t.Fatalf("consolidated report failed %v", err)
+	}
+	// 10.00 EUR is 11.00 USD, plus 20.00 USD
+	if report.Total != usd(31) || report.Revenue[slot.SCOOTER] != usd(11) || report.Receipts != 2 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if _, err := plot.ConsolidatedReport("JPY", rates); !errors.Is(err, money.ErrNoRate) {
+		t.Errorf("expected ErrNoRate, got %v", err)
+	}
+}
+
+func TestMixedCurrencyTariff(t *testing.T) {
+	mixed := tariff.NewMultipleTariffMatcher()
+	mixed.Append(tariff.NewEveryHour(usd(2)))
+	mixed.Append(tariff.NewEveryHour(money.New(100, "EUR")))
+	config := NewParkingConfig(slot.SUV, 1, mixed)
+	if err := config.Validate(); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	if _, err := OpenParkingLot([]*ParkingConfig{config}, memoryStore{}); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("open must reject the config, got %v", err)
+	}
+	if _, err := NewNamedParkingConfig("Suv", 1, mixed); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("named config must be rejected, got %v", err)
+	}
+
+	// the lot refuses the vehicle type instead of failing at the exit
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot([]*ParkingConfig{config, NewParkingConfig(slot.SCOOTER, 1, euroTariff(500))}, WithClock(clock))
+	if _, err := plot.Park(slot.NewRoadVehicle(slot.SUV)); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("park must be refused, got %v", err)
+	}
+	if _, err := plot.Quote(slot.SUV, clock.Now(), clock.Now().Add(time.Hour)); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("quote must be refused, got %v", err)
+	}
+	if receipt := stay(t, plot, clock, slot.SCOOTER, time.Hour); receipt.GetCost() != money.New(500, "EUR") {
+		t.Errorf("other vehicle types must park %v", receipt)
+	}
+}
+
+// recordingStore : keeps the journaled records in memory
+type recordingStore struct {
+	memoryStore
+	records *[]Record
+}
+
+func (store recordingStore) Append(record Record) error {
+	*store.records = append(*store.records, record)
+	return nil
+}
+
+func TestNotInRange(t *testing.T) {
+	// no model prices stays of 4h and more
+	shortStay := tariff.NewSingleTariffMatcher()
+	shortStay.Append(tariff.NewHourInterval(usd(5), tariff.NewTimeConstraint(0, tariff.HrtoMinutes(4))))
+	config := NewParkingConfig(slot.SUV, 1, shortStay)
+	if err := config.SetCharges(charges.NewPipeline(charges.NewSurcharge("Facility fee", usd(2)))); err != nil {
+		t.Fatalf("charges failed %v", err)
+	}
+	var records []Record
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot, err := OpenParkingLot([]*ParkingConfig{config}, recordingStore{records: &records}, WithClock(clock))
+	if err != nil {
+		t.Fatalf("open parking lot failed %v", err)
+	}
+	ticket, _ := plot.Park(slot.NewRegisteredVehicle(slot.SUV, "AB12CD", nil))
+	clock.Advance(time.Hour * 5)
+
+	var notInRange *tariff.NotInRangeError
+	if _, err := plot.UnPark(ticket); !errors.Is(err, ErrNotInRange) || !errors.As(err, &notInRange) {
+		t.Errorf("expected ErrNotInRange, got %v", err)
+	}
+	if _, err := plot.LostTicketExit(LostTicket{Plate: "AB12CD"}); !errors.Is(err, ErrNotInRange) {
+		t.Errorf("expected ErrNotInRange, got %v", err)
+	}
+	if _, err := plot.QuoteTicket(ticket, clock.Now()); !errors.Is(err, ErrNotInRange) {
+		t.Errorf("expected ErrNotInRange, got %v", err)
+	}
+	if _, err := plot.Quote(slot.SUV, clock.Now(), clock.Now().Add(time.Hour*6)); !errors.Is(err, ErrNotInRange) {
+		t.Errorf("expected ErrNotInRange, got %v", err)
+	}
+	if len(records) != 1 || records[0].Op != OpPark {
+		t.Errorf("only the park may be journaled %v", records)
+	}
+	if report, _ := plot.Report(); report.Receipts != 0 || report.Total != usd(0) {
+		t.Errorf("nothing may be booked %+v", report)
+	}
+	// the ticket stays open
+	if err := plot.VoidTicket(ticket); err != nil {
+		t.Errorf("ticket must stay open %v", err)
+	}
+}
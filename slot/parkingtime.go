@@ -6,6 +6,22 @@ import (
 	"time"
 )
 
+var ErrInvalidOutTime = errors.New("invalid out-time")
+
+// InvalidOutTimeError : out-time is before the in-time, matches ErrInvalidOutTime
+type InvalidOutTimeError struct {
+	InTime  time.Time
+	OutTime time.Time
+}
+
+func (invalidOutTimeError *InvalidOutTimeError) Error() string {
+	return fmt.Sprintf("invalid time in-time %v , out-time %v", invalidOutTimeError.InTime, invalidOutTimeError.OutTime)
+}
+
+func (invalidOutTimeError *InvalidOutTimeError) Is(target error) bool {
+	return target == ErrInvalidOutTime
+}
+
 type ParkingTime interface {
 	GetInTime() time.Time
 	SetInTime(time time.Time)
@@ -55,7 +71,7 @@ func (vehicleParkingTime *VehicleParkingTime) CalculateHours() float64 {
 func (vehicleParkingTime *VehicleParkingTime) validateOutTime(outTime time.Time) error {
 	diff := outTime.Sub(vehicleParkingTime.inTime)
 	if diff < 0 {
-		return errors.New(fmt.Sprintf("invalid time in-time %v , out-time %v ", vehicleParkingTime.inTime, vehicleParkingTime.outTime))
+		return &InvalidOutTimeError{InTime: vehicleParkingTime.inTime, OutTime: outTime}
 	}
 	return nil
 }
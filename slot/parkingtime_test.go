@@ -1,6 +1,7 @@
 package slot
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -18,6 +19,10 @@ func TestParkingTime(t *testing.T) {
 	if err == nil {
 		t.Errorf("Failed to validate  ")
 	}
+	var invalidOutTime *InvalidOutTimeError
+	if !errors.Is(err, ErrInvalidOutTime) || !errors.As(err, &invalidOutTime) || invalidOutTime.OutTime != timeVal {
+		t.Errorf("Failed : expected InvalidOutTimeError %v ", err)
+	}
 
 	// calculate hour and minute
 	timeVal = time.Now()
@@ -2,39 +2,114 @@ package slot
 
 import (
 	"fmt"
+	"github.com/hbkkanna/parking/money"
+	"strings"
 )
 
 type Receipt interface {
 	Slot
 	GetReceiptNumber() int
-	GetCost() float64
+	GetCost() money.Money
+	GetNet() money.Money
+	GetTax() money.Money
+	GetGross() money.Money
+	GetLines() []ReceiptLine
+}
+
+// kinds of receipt lines
+const (
+	LineTariff    = "tariff"
+	LineSurcharge = "surcharge"
+	LineTax       = "tax"
+	LineRounding  = "rounding"
+)
+
+// ReceiptLine : one itemized charge on the receipt
+type ReceiptLine struct {
+	Kind        string
+	Description string
+	Quantity    float64
+	Unit        string
+	Amount      money.Money
+}
+
+func (receiptLine ReceiptLine) String() string {
+	if receiptLine.Quantity == 1 {
+		return fmt.Sprintf("%s: %s", receiptLine.Description, receiptLine.Amount.Format())
+	}
+	return fmt.Sprintf("%s: %v %s: %s", receiptLine.Description, receiptLine.Quantity, receiptLine.Unit, receiptLine.Amount.Format())
+}
+
+// Totals : net amount before tax, the tax and the gross amount paid,
+// gross differs from net plus tax by the cash rounding
+type Totals struct {
+	Net   money.Money
+	Tax   money.Money
+	Gross money.Money
 }
 
 type VehicleReceipt struct {
 	Slot
-	cost          float64
+	totals        Totals
 	receiptNumber int
+	lines         []ReceiptLine
 }
 
 func (vehicleReceipt *VehicleReceipt) GetReceiptNumber() int {
 	return vehicleReceipt.receiptNumber
 }
 
-func (vehicleReceipt *VehicleReceipt) GetCost() float64 {
-	return vehicleReceipt.cost
+// GetCost returns the gross amount paid
+func (vehicleReceipt *VehicleReceipt) GetCost() money.Money {
+	return vehicleReceipt.totals.Gross
+}
+
+func (vehicleReceipt *VehicleReceipt) GetNet() money.Money {
+	return vehicleReceipt.totals.Net
+}
+
+func (vehicleReceipt *VehicleReceipt) GetTax() money.Money {
+	return vehicleReceipt.totals.Tax
+}
+
+func (vehicleReceipt *VehicleReceipt) GetGross() money.Money {
+	return vehicleReceipt.totals.Gross
+}
+
+func (vehicleReceipt *VehicleReceipt) GetLines() []ReceiptLine {
+	return vehicleReceipt.lines
 }
 
 func (vehicleReceipt *VehicleReceipt) String() string {
-	return fmt.Sprintf("Parking Receipt: \n  Receipt Number: R-%d \n  "+
-		"Entry Date-Time: %v \n  Exit Date-Time: %v \n  Cost: %.2f",
-		vehicleReceipt.GetReceiptNumber(), vehicleReceipt.GetInTime(),
-		vehicleReceipt.GetOutTime(), vehicleReceipt.cost)
+	var lines strings.Builder
+	for _, line := range vehicleReceipt.lines {
+		lines.WriteString("\n    " + line.String())
+	}
+	totals := vehicleReceipt.totals
+	cost := totals.Gross.Format()
+	if !totals.Tax.IsZero() || totals.Net != totals.Gross {
+		cost = fmt.Sprintf("%s (Net: %s, Tax: %s)", cost, totals.Net.Format(), totals.Tax.Format())
+	}
+	plate := ""
+	if vehicleReceipt.GetPlate() != "" {
+		plate = fmt.Sprintf("Plate: %s \n  ", vehicleReceipt.GetPlate())
+	}
+	return fmt.Sprintf("Parking Receipt: \n  Receipt Number: R-%d \n  %s"+
+		"Entry Date-Time: %v \n  Exit Date-Time: %v \n  Cost: %s%s",
+		vehicleReceipt.GetReceiptNumber(), plate, vehicleReceipt.GetInTime(),
+		vehicleReceipt.GetOutTime(), cost, lines.String())
+}
+
+// NewReceipt creates an untaxed receipt, the cost is both net and gross
+func NewReceipt(receiptNumber int, cost money.Money, slot Slot, lines ...ReceiptLine) Receipt {
+	return NewTaxedReceipt(receiptNumber, Totals{Net: cost, Tax: money.New(0, cost.Currency()), Gross: cost}, slot, lines...)
 }
 
-func NewReceipt(receiptNumber int, cost float64, slot Slot) Receipt {
+func NewTaxedReceipt(receiptNumber int, totals Totals, slot Slot, lines ...ReceiptLine) Receipt {
 	return &VehicleReceipt{
 		Slot:          slot,
 		receiptNumber: receiptNumber,
-		cost:          cost,
+		totals:        totals,
+		lines:         lines,
 	}
 }
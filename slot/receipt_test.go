@@ -1 +1,47 @@
 package slot
+
+import (
+	"github.com/hbkkanna/parking/money"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReceiptLines(t *testing.T) {
+	vehicleSlot := NewVehicleSlot(NewRoadVehicle(SUV), 3)
+	inTime := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	vehicleSlot.SetInTime(inTime)
+	vehicleSlot.SetOutTime(inTime.Add(time.Hour * 27))
+
+	cost := money.New(20000, "USD")
+	receipt := NewReceipt(7, cost, vehicleSlot, ReceiptLine{Description: "EveryDay", Quantity: 2, Unit: "day", Amount: cost})
+	if len(receipt.GetLines()) != 1 || receipt.GetLines()[0].Amount != cost {
+		t.Errorf("receipt lines failed")
+	}
+	if !strings.HasSuffix(receipt.(*VehicleReceipt).String(), "Cost: $200.00\n    EveryDay: 2 day: $200.00") {
+		t.Errorf("receipt lines not rendered %v", receipt)
+	}
+}
+
+func TestReceiptCurrencyFormat(t *testing.T) {
+	receipt := NewReceipt(1, money.New(400, "JPY"), NewVehicleSlot(NewRoadVehicle(SUV), 3))
+	if !strings.HasSuffix(receipt.(*VehicleReceipt).String(), "Cost: ¥400") {
+		t.Errorf("receipt currency not rendered %v", receipt)
+	}
+	receipt = NewReceipt(2, money.New(1250, "CHF"), NewVehicleSlot(NewRoadVehicle(SUV), 3))
+	if !strings.HasSuffix(receipt.(*VehicleReceipt).String(), "Cost: 12.50 CHF") {
+		t.Errorf("receipt currency not rendered %v", receipt)
+	}
+}
+
+func TestReceiptTotals(t *testing.T) {
+	totals := Totals{Net: money.New(1200, "EUR"), Tax: money.New(240, "EUR"), Gross: money.New(1440, "EUR")}
+	receipt := NewTaxedReceipt(3, totals, NewVehicleSlot(NewRoadVehicle(SUV), 3),
+		ReceiptLine{Kind: LineTax, Description: "VAT 20%", Quantity: 1, Amount: totals.Tax})
+	if receipt.GetCost() != totals.Gross || receipt.GetNet() != totals.Net || receipt.GetTax() != totals.Tax {
+		t.Errorf("receipt totals failed %v", receipt)
+	}
+	if !strings.HasSuffix(receipt.(*VehicleReceipt).String(), "Cost: €14.40 (Net: €12.00, Tax: €2.40)\n    VAT 20%: €2.40") {
+		t.Errorf("receipt totals not rendered %v", receipt)
+	}
+}
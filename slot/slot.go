@@ -1,43 +1,75 @@
 package slot
 
 import (
+	"strings"
 	"time"
 )
 
+// built in vehicle types, more are added with RegisterVehicleType
 const (
 	SCOOTER = iota
 	SUV
 	TRUCK
 )
 
+// Vehicles holds a shared vehicle of each built in type, use NewRoadVehicle for registered types
 var Vehicles map[int]Vehicle
-var vehiclesStr map[int]string
 
 func init() {
+	for _, builtIn := range []struct {
+		name string
+		size SizeClass
+	}{{"Scooter", SizeSmall}, {"Suv", SizeMedium}, {"Truck", SizeLarge}} {
+		if _, err := RegisterVehicleType(builtIn.name, builtIn.size, nil); err != nil {
+			panic(err)
+		}
+	}
 	Vehicles = map[int]Vehicle{SCOOTER: NewRoadVehicle(SCOOTER), SUV: NewRoadVehicle(SUV), TRUCK: NewRoadVehicle(TRUCK)}
-	vehiclesStr = map[int]string{SCOOTER: "Scooter", SUV: "Suv", TRUCK: "Truck"}
 }
 
+// Vehicle : vehicle type and registration plate, the plate is empty for unregistered vehicles
 type Vehicle interface {
 	GetVehicleType() int
+	GetPlate() string
 }
 
 type RoadVehicle struct {
 	vehicleType int
+	plate       string
+	metadata    map[string]string
 }
 
 func (vehicle *RoadVehicle) GetVehicleType() int {
 	return vehicle.vehicleType
 }
 
+func (vehicle *RoadVehicle) GetPlate() string {
+	return vehicle.plate
+}
+
+// GetMetadata returns the free form details of the vehicle, e.g. make or colour
+func (vehicle *RoadVehicle) GetMetadata() map[string]string {
+	return vehicle.metadata
+}
+
 func (vehicle *RoadVehicle) String() string {
-	return vehiclesStr[vehicle.vehicleType]
+	if vehicle.plate != "" {
+		return getVehicleTypeName(vehicle.vehicleType) + " " + vehicle.plate
+	}
+	return getVehicleTypeName(vehicle.vehicleType)
+}
+
+// NormalizePlate returns the plate in upper case without spaces and dashes, plates are compared normalized
+func NormalizePlate(plate string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(plate))
 }
 
 type Slot interface {
 	Vehicle
 	ParkingTime
 	GetNumber() int
+	// SetPlate records the plate of the parked vehicle, Reset clears it
+	SetPlate(plate string)
 	Reset()
 	IsFree() bool
 }
@@ -46,12 +78,22 @@ type VehicleSlot struct {
 	Vehicle
 	ParkingTime
 	number int
+	plate  string
 }
 
 func (vehicleSlot *VehicleSlot) GetNumber() int {
 	return vehicleSlot.number
 }
 
+// GetPlate returns the plate of the parked vehicle
+func (vehicleSlot *VehicleSlot) GetPlate() string {
+	return vehicleSlot.plate
+}
+
+func (vehicleSlot *VehicleSlot) SetPlate(plate string) {
+	vehicleSlot.plate = plate
+}
+
 func (vehicleSlot *VehicleSlot) IsFree() bool {
 	zeroVal := time.Time{}
 	return vehicleSlot.GetInTime() == zeroVal
@@ -60,6 +102,7 @@ func (vehicleSlot *VehicleSlot) IsFree() bool {
 func (vehicleSlot *VehicleSlot) Reset() {
 	vehicleSlot.SetOutTime(time.Time{})
 	vehicleSlot.SetInTime(time.Time{})
+	vehicleSlot.plate = ""
 }
 
 func NewVehicleSlot(vehicle Vehicle, number int) Slot {
@@ -74,6 +117,7 @@ func CloneVehicleSlot(vehicleSlot Slot) Slot {
 	clonedSlot := NewVehicleSlot(NewRoadVehicle(vehicleSlot.GetVehicleType()), vehicleSlot.GetNumber())
 	clonedSlot.SetInTime(vehicleSlot.GetInTime())
 	clonedSlot.SetOutTime(vehicleSlot.GetOutTime())
+	clonedSlot.SetPlate(vehicleSlot.GetPlate())
 	return clonedSlot
 }
 
@@ -82,3 +126,12 @@ func NewRoadVehicle(vehicleType int) Vehicle {
 		vehicleType: vehicleType,
 	}
 }
+
+// NewRegisteredVehicle creates a vehicle identified by its plate, metadata is optional
+func NewRegisteredVehicle(vehicleType int, plate string, metadata map[string]string) Vehicle {
+	return &RoadVehicle{
+		vehicleType: vehicleType,
+		plate:       plate,
+		metadata:    metadata,
+	}
+}
@@ -26,3 +26,35 @@ func TestSlot(t *testing.T) {
 		fmt.Println("clone working")
 	}
 }
+
+func TestGetVehicleTypeByName(t *testing.T) {
+	if vehicleType, ok := GetVehicleTypeByName("suv"); !ok || vehicleType != SUV {
+		t.Errorf("lookup by name failed")
+	}
+	if _, ok := GetVehicleTypeByName("bicycle"); ok {
+		t.Errorf("unknown name found")
+	}
+}
+
+func TestVehiclePlate(t *testing.T) {
+	vehicle := NewRegisteredVehicle(SUV, "ab-12 cd", map[string]string{"colour": "red"})
+	if vehicle.GetPlate() != "ab-12 cd" || NormalizePlate(vehicle.GetPlate()) != "AB12CD" {
+		t.Errorf("unexpected plate %s", vehicle.GetPlate())
+	}
+	if vehicle.(*RoadVehicle).GetMetadata()["colour"] != "red" {
+		t.Errorf("metadata not kept")
+	}
+
+	// the ticket keeps the plate after the slot is released
+	slt := NewVehicleSlot(NewRoadVehicle(SUV), 1)
+	slt.SetInTime(time.Now())
+	slt.SetPlate(vehicle.GetPlate())
+	ticket := NewTicket(1, slt)
+	if CloneVehicleSlot(slt).GetPlate() != "ab-12 cd" {
+		t.Errorf("clone lost the plate")
+	}
+	slt.Reset()
+	if slt.GetPlate() != "" || ticket.GetPlate() != "ab-12 cd" {
+		t.Errorf("unexpected plates slot %q ticket %q", slt.GetPlate(), ticket.GetPlate())
+	}
+}
@@ -12,20 +12,32 @@ type Ticket interface {
 type VehicleTicket struct {
 	Slot
 	ticketNumber int
+	plate        string
 }
 
 func (vehicleTicket *VehicleTicket) GetTicketNumber() int {
 	return vehicleTicket.ticketNumber
 }
 
+// GetPlate returns the plate parked with the ticket, kept after the slot is released
+func (vehicleTicket *VehicleTicket) GetPlate() string {
+	return vehicleTicket.plate
+}
+
 func (vehicleTicket *VehicleTicket) String() string {
+	plate := ""
+	if vehicleTicket.plate != "" {
+		plate = fmt.Sprintf("\n  Plate: %s ", vehicleTicket.plate)
+	}
 	return fmt.Sprintf("Parking Ticket: \n  Ticket Number: %d \n  Spot Number: %d \n  "+
-		"Entry Date-Time: %v ", vehicleTicket.GetTicketNumber(), vehicleTicket.GetNumber(), vehicleTicket.GetInTime())
+		"Entry Date-Time: %v %s", vehicleTicket.GetTicketNumber(), vehicleTicket.GetNumber(), vehicleTicket.GetInTime(), plate)
 }
 
+// NewTicket creates the ticket of the slot, the plate is taken from the slot
 func NewTicket(ticketNumber int, slot Slot) Ticket {
 	return &VehicleTicket{
 		Slot:         slot,
 		ticketNumber: ticketNumber,
+		plate:        slot.GetPlate(),
 	}
 }
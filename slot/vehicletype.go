@@ -0,0 +1,170 @@
+package slot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownVehicleType   = errors.New("unknown vehicle type")
+	ErrDuplicateVehicleType = errors.New("duplicate vehicle type")
+	ErrInvalidSizeClass     = errors.New("invalid size class")
+	ErrBuiltInVehicleType   = errors.New("built in vehicle type")
+)
+
+// SizeClass : physical size of a vehicle type, a larger class needs a larger slot
+type SizeClass int
+
+const (
+	SizeSmall SizeClass = iota
+	SizeMedium
+	SizeLarge
+)
+
+var sizeClassStr = map[SizeClass]string{SizeSmall: "Small", SizeMedium: "Medium", SizeLarge: "Large"}
+
+func (sizeClass SizeClass) String() string {
+	if name, ok := sizeClassStr[sizeClass]; ok {
+		return name
+	}
+	return fmt.Sprintf("SizeClass(%d)", int(sizeClass))
+}
+
+// ParseSizeClass reads the size class name, case insensitive
+func ParseSizeClass(name string) (SizeClass, error) {
+	for sizeClass, sizeName := range sizeClassStr {
+		if strings.EqualFold(sizeName, name) {
+			return sizeClass, nil
+		}
+	}
+	return 0, fmt.Errorf("%w %q", ErrInvalidSizeClass, name)
+}
+
+func (sizeClass SizeClass) MarshalText() ([]byte, error) {
+	if _, ok := sizeClassStr[sizeClass]; !ok {
+		return nil, fmt.Errorf("%w %d", ErrInvalidSizeClass, int(sizeClass))
+	}
+	return []byte(sizeClass.String()), nil
+}
+
+func (sizeClass *SizeClass) UnmarshalText(text []byte) error {
+	parsed, err := ParseSizeClass(string(text))
+	if err != nil {
+		return err
+	}
+	*sizeClass = parsed
+	return nil
+}
+
+// VehicleType : registered vehicle type, Metadata holds display details such as an icon or a colour
+type VehicleType struct {
+	ID       int
+	Name     string
+	Size     SizeClass
+	Metadata map[string]string
+}
+
+// vehicleTypeRegistry : vehicle types by id, names are unique ignoring case
+type vehicleTypeRegistry struct {
+	mutex sync.RWMutex
+	types map[int]VehicleType
+	next  int
+}
+
+var vehicleTypes = &vehicleTypeRegistry{types: make(map[int]VehicleType)}
+
+func (registry *vehicleTypeRegistry) register(name string, size SizeClass, metadata map[string]string) (int, error) {
+	if strings.TrimSpace(name) == "" {
+		return 0, fmt.Errorf("%w: empty name", ErrUnknownVehicleType)
+	}
+	if _, ok := sizeClassStr[size]; !ok {
+		return 0, fmt.Errorf("%w %d", ErrInvalidSizeClass, int(size))
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	for _, vehicleType := range registry.types {
+		if strings.EqualFold(vehicleType.Name, name) {
+			return 0, fmt.Errorf("%w %q", ErrDuplicateVehicleType, name)
+		}
+	}
+	id := registry.next
+	registry.types[id] = VehicleType{ID: id, Name: name, Size: size, Metadata: metadata}
+	registry.next++
+	return id, nil
+}
+
+// RegisterVehicleType adds a vehicle type at runtime and returns its id, the built in
+// Scooter, Suv and Truck are registered as SCOOTER, SUV and TRUCK
+func RegisterVehicleType(name string, size SizeClass, metadata map[string]string) (int, error) {
+	return vehicleTypes.register(name, size, metadata)
+}
+
+func (registry *vehicleTypeRegistry) unregister(id int) error {
+	if id <= TRUCK {
+		return fmt.Errorf("%w %d", ErrBuiltInVehicleType, id)
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if _, ok := registry.types[id]; !ok {
+		return fmt.Errorf("%w %d", ErrUnknownVehicleType, id)
+	}
+	delete(registry.types, id)
+	return nil
+}
+
+// UnregisterVehicleType removes a vehicle type added with RegisterVehicleType, e.g. when the config
+// that added it is rejected, no lot may still use it. The built in types can not be removed.
+func UnregisterVehicleType(id int) error {
+	return vehicleTypes.unregister(id)
+}
+
+// GetVehicleType returns the registered vehicle type of the id
+func GetVehicleType(id int) (VehicleType, bool) {
+	vehicleTypes.mutex.RLock()
+	defer vehicleTypes.mutex.RUnlock()
+	vehicleType, ok := vehicleTypes.types[id]
+	return vehicleType, ok
+}
+
+// LookupVehicleType returns the registered vehicle type of the name, case insensitive
+func LookupVehicleType(name string) (VehicleType, error) {
+	vehicleTypes.mutex.RLock()
+	defer vehicleTypes.mutex.RUnlock()
+	for _, vehicleType := range vehicleTypes.types {
+		if strings.EqualFold(vehicleType.Name, name) {
+			return vehicleType, nil
+		}
+	}
+	return VehicleType{}, fmt.Errorf("%w %q", ErrUnknownVehicleType, name)
+}
+
+// GetVehicleTypeByName looks up the vehicle type of the display name, case insensitive
+func GetVehicleTypeByName(name string) (int, bool) {
+	vehicleType, err := LookupVehicleType(name)
+	return vehicleType.ID, err == nil
+}
+
+// GetVehicleTypes returns the registered vehicle types ordered by id
+func GetVehicleTypes() []VehicleType {
+	vehicleTypes.mutex.RLock()
+	defer vehicleTypes.mutex.RUnlock()
+	var registered []VehicleType
+	for _, vehicleType := range vehicleTypes.types {
+		registered = append(registered, vehicleType)
+	}
+	sort.Slice(registered, func(i, j int) bool {
+		return registered[i].ID < registered[j].ID
+	})
+	return registered
+}
+
+// getVehicleTypeName returns the registered name, or the id of an unregistered type
+func getVehicleTypeName(id int) string {
+	if vehicleType, ok := GetVehicleType(id); ok {
+		return vehicleType.Name
+	}
+	return fmt.Sprintf("VehicleType(%d)", id)
+}
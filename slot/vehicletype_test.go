@@ -0,0 +1,76 @@
+package slot
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestRegisterVehicleType(t *testing.T) {
+	bicycle, err := RegisterVehicleType("Bicycle", SizeSmall, map[string]string{"icon": "bike"})
+	if err != nil || bicycle <= TRUCK {
+		t.Fatalf("register failed %d %v", bicycle, err)
+	}
+	t.Cleanup(func() { UnregisterVehicleType(bicycle) })
+	registered, ok := GetVehicleType(bicycle)
+	if !ok || registered.Name != "Bicycle" || registered.Size != SizeSmall || registered.Metadata["icon"] != "bike" {
+		t.Errorf("unexpected vehicle type %+v", registered)
+	}
+	if vehicleType, ok := GetVehicleTypeByName("BICYCLE"); !ok || vehicleType != bicycle {
+		t.Errorf("lookup by name failed")
+	}
+	if name := NewRegisteredVehicle(bicycle, "B1", nil).(*RoadVehicle).String(); name != "Bicycle B1" {
+		t.Errorf("unexpected vehicle name %s", name)
+	}
+
+	if _, err := RegisterVehicleType("suv", SizeLarge, nil); !errors.Is(err, ErrDuplicateVehicleType) {
+		t.Errorf("expected ErrDuplicateVehicleType, got %v", err)
+	}
+	if _, err := RegisterVehicleType("Hovercraft", SizeClass(7), nil); !errors.Is(err, ErrInvalidSizeClass) {
+		t.Errorf("expected ErrInvalidSizeClass, got %v", err)
+	}
+	if _, err := LookupVehicleType("Hovercraft"); !errors.Is(err, ErrUnknownVehicleType) {
+		t.Errorf("expected ErrUnknownVehicleType, got %v", err)
+	}
+	if err := UnregisterVehicleType(SUV); !errors.Is(err, ErrBuiltInVehicleType) {
+		t.Errorf("expected ErrBuiltInVehicleType, got %v", err)
+	}
+	types := GetVehicleTypes()
+	if len(types) < 4 || types[SUV].Name != "Suv" || types[SUV].Size != SizeMedium {
+		t.Errorf("unexpected vehicle types %v", types)
+	}
+}
+
+func TestSizeClassText(t *testing.T) {
+	var size SizeClass
+	if err := json.Unmarshal([]byte(`"large"`), &size); err != nil || size != SizeLarge {
+		t.Errorf("unmarshal failed %v %v", size, err)
+	}
+	if data, err := json.Marshal(SizeMedium); err != nil || string(data) != `"Medium"` {
+		t.Errorf("marshal failed %s %v", data, err)
+	}
+	if _, err := ParseSizeClass("Huge"); !errors.Is(err, ErrInvalidSizeClass) {
+		t.Errorf("expected ErrInvalidSizeClass, got %v", err)
+	}
+}
+
+func TestUnregisterVehicleType(t *testing.T) {
+	tram, err := RegisterVehicleType("Tram", SizeLarge, nil)
+	if err != nil {
+		t.Fatalf("register failed %v", err)
+	}
+	if err := UnregisterVehicleType(tram); err != nil {
+		t.Fatalf("unregister failed %v", err)
+	}
+	if _, ok := GetVehicleTypeByName("Tram"); ok {
+		t.Errorf("unregistered type found")
+	}
+	if err := UnregisterVehicleType(tram); !errors.Is(err, ErrUnknownVehicleType) {
+		t.Errorf("expected ErrUnknownVehicleType, got %v", err)
+	}
+	// the name can be registered again
+	if tram, err = RegisterVehicleType("Tram", SizeMedium, nil); err != nil {
+		t.Fatalf("register again failed %v", err)
+	}
+	UnregisterVehicleType(tram)
+}
@@ -0,0 +1,59 @@
+package parking
+
+import (
+	"github.com/hbkkanna/parking/money"
+	"time"
+)
+
+const (
+	OpPark   = "park"
+	OpUnPark = "unpark"
+	OpVoid   = "void"
+	// OpLostTicket : exit without the ticket, billed like an unpark, the ticket is voided
+	OpLostTicket = "lost"
+)
+
+// Record : one journaled lot operation, written before the lot state changes
+type Record struct {
+	Sequence      int64        `json:"seq"`
+	Op            string       `json:"op"`
+	TicketNumber  int          `json:"ticket"`
+	ReceiptNumber int          `json:"receipt,omitempty"`
+	VehicleType   int          `json:"vehicleType"`
+	SlotNumber    int          `json:"slot"`
+	Time          time.Time    `json:"time"`
+	Cost          *money.Money `json:"cost,omitempty"`
+	Plate         string       `json:"plate,omitempty"`
+	// TariffType is set when the stay is billed with the tariff of another vehicle type than the slot
+	TariffType *int `json:"tariffType,omitempty"`
+}
+
+// TicketState : issued ticket in a snapshot, in-time is set only for tickets still parked
+type TicketState struct {
+	TicketNumber int       `json:"ticket"`
+	VehicleType  int       `json:"vehicleType"`
+	SlotNumber   int       `json:"slot"`
+	Status       int       `json:"status"`
+	InTime       time.Time `json:"inTime,omitempty"`
+	Plate        string    `json:"plate,omitempty"`
+	TariffType   *int      `json:"tariffType,omitempty"`
+}
+
+// Snapshot : complete lot state up to and including the journal sequence
+type Snapshot struct {
+	Sequence   int64         `json:"seq"`
+	TicketCnt  int64         `json:"ticketCnt"`
+	ReceiptCnt int64         `json:"receiptCnt"`
+	Tickets    []TicketState `json:"tickets"`
+	// Revenue and Receipts by vehicle type, only vehicle types with receipts are listed
+	Revenue  map[int]money.Money `json:"revenue,omitempty"`
+	Receipts map[int]int         `json:"receipts,omitempty"`
+}
+
+// Store persists the lot journal and snapshots. Load returns the latest snapshot, nil when none
+// was saved, and the journal records written after it.
+type Store interface {
+	Append(record Record) error
+	Save(snapshot Snapshot) error
+	Load() (*Snapshot, []Record, error)
+}
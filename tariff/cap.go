@@ -0,0 +1,203 @@
+package tariff
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"time"
+)
+
+var ErrInvalidCap = errors.New("invalid price cap")
+
+const (
+	DailyCapModel = "DailyCap"
+	StayCapModel  = "StayCap"
+)
+
+// CapPeriod : how stays are split into days for the daily cap
+type CapPeriod string
+
+const (
+	// RollingDay caps every 24h from the in-time
+	RollingDay CapPeriod = "Rolling24h"
+	// CalendarDay caps every calendar day in the location of the in-time
+	CalendarDay CapPeriod = "CalendarDay"
+)
+
+// Caps : most charged per day and per stay, zero amounts do not cap
+type Caps struct {
+	Daily  money.Money
+	Period CapPeriod // RollingDay when empty
+	Stay   money.Money
+}
+
+// CappedTariff : caps the cost of the wrapped tariff. The stay is priced as a whole with the
+// wrapped tariff, with a daily cap the cost that accrues within each day is capped and the stay
+// cap then limits the total, so a cap never raises the price. Each cap that applies is a line
+// item with the discount as a negative subtotal, Append adds to the wrapped tariff.
+type CappedTariff struct {
+	base Tariff
+	caps Caps
+}
+
+func (cappedTariff *CappedTariff) GetCost(parkingTime slot.ParkingTime) money.Money {
+	return getCost(cappedTariff.Itemize(parkingTime), cappedTariff.GetCurrency())
+}
+
+func (cappedTariff *CappedTariff) Itemize(parkingTime slot.ParkingTime) []LineItem {
+	items := cappedTariff.base.Itemize(parkingTime)
+	if items == nil {
+		return nil
+	}
+	if !cappedTariff.caps.Daily.IsZero() {
+		inTime := parkingTime.GetInTime()
+		uncapped := getCost(items, cappedTariff.GetCurrency())
+		charged := money.New(0, cappedTariff.GetCurrency())
+		for _, day := range cappedTariff.split(inTime, parkingTime.GetOutTime()) {
+			// the cost accrued by the end of the day, the whole stay on its last day
+			accrued := uncapped
+			if day.GetOutTime().Before(parkingTime.GetOutTime()) {
+				dayItems := cappedTariff.base.Itemize(stayUntil(inTime, day.GetOutTime()))
+				if dayItems == nil {
+					return nil
+				}
+				accrued = getCost(dayItems, cappedTariff.GetCurrency())
+			}
+			start := day.GetInTime().Sub(inTime).Minutes()
+			end := day.GetOutTime().Sub(inTime).Minutes()
+			items = appendCap(items, accrued.Sub(charged), DailyCapModel, NewTimeConstraint(start, end), cappedTariff.caps.Daily)
+			charged = accrued
+		}
+	}
+	if cappedTariff.caps.Stay.IsZero() {
+		return items
+	}
+	stay := NewTimeConstraint(0, parkingTime.CalculateMinutes())
+	return appendCap(items, getCost(items, cappedTariff.GetCurrency()), StayCapModel, stay, cappedTariff.caps.Stay)
+}
+
+// stayUntil returns the stay from the in-time to the out-time
+func stayUntil(inTime time.Time, outTime time.Time) slot.ParkingTime {
+	stay := slot.NewParkingTime()
+	stay.SetInTime(inTime)
+	_ = stay.SetOutTime(outTime)
+	return stay
+}
+
+// appendCap adds the discount when the cost exceeds the cap
+func appendCap(items []LineItem, cost money.Money, model string, constraint TimeConstraint, cap money.Money) []LineItem {
+	if cost.Cmp(cap) <= 0 {
+		return items
+	}
+	capItem := newLineItem(model, constraint, 1, UnitFlat, cap)
+	capItem.Subtotal = cap.Sub(cost)
+	return append(items, capItem)
+}
+
+// split returns the days of the stay, a stay shorter than a day is one day
+func (cappedTariff *CappedTariff) split(inTime time.Time, outTime time.Time) []slot.ParkingTime {
+	var days []slot.ParkingTime
+	start := inTime
+	for {
+		end := start.Add(24 * time.Hour)
+		if cappedTariff.caps.Period == CalendarDay {
+			end = time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, start.Location())
+		}
+		if !end.Before(outTime) {
+			end = outTime
+		}
+		days = append(days, stayUntil(start, end))
+		if !end.Before(outTime) {
+			return days
+		}
+		start = end
+	}
+}
+
+func (cappedTariff *CappedTariff) Append(calculator ModelCalculator) {
+	cappedTariff.base.Append(calculator)
+}
+
+// GetModels returns the models of the wrapped tariff
+func (cappedTariff *CappedTariff) GetModels() []ModelCalculator {
+	return cappedTariff.base.GetModels()
+}
+
+func (cappedTariff *CappedTariff) GetCurrency() string {
+	return cappedTariff.base.GetCurrency()
+}
+
+func (cappedTariff *CappedTariff) chains() []Tariff {
+	return []Tariff{cappedTariff.base}
+}
+
+func (cappedTariff *CappedTariff) GetCaps() Caps {
+	return cappedTariff.caps
+}
+
+// GetSpec describes the wrapped tariff with its caps
+func (cappedTariff *CappedTariff) GetSpec() (TariffSpec, error) {
+	spec, err := cappedTariff.base.GetSpec()
+	if err != nil {
+		return TariffSpec{}, err
+	}
+	spec.Caps = cappedTariff.caps.GetSpec()
+	return spec, nil
+}
+
+// NewCappedTariff caps any model or tariff, a model is wrapped in a SingleTariffMatcher.
+// The caps have to be positive and priced in the currency of the tariff.
+func NewCappedTariff(calculator ModelCalculator, caps Caps) (*CappedTariff, error) {
+	base, ok := calculator.(Tariff)
+	if !ok {
+		base = NewSingleTariffMatcher()
+		base.Append(calculator)
+	}
+	if caps.Period == "" {
+		caps.Period = RollingDay
+	}
+	if caps.Period != RollingDay && caps.Period != CalendarDay {
+		return nil, fmt.Errorf("%w: unknown period %q", ErrInvalidCap, caps.Period)
+	}
+	for _, cap := range []money.Money{caps.Daily, caps.Stay} {
+		if cap.IsNegative() {
+			return nil, fmt.Errorf("%w: %v must not be negative", ErrInvalidCap, cap)
+		}
+		if !cap.IsZero() && cap.Currency() != base.GetCurrency() {
+			return nil, fmt.Errorf("%w: cap %v, tariff in %s", money.ErrCurrencyMismatch, cap, base.GetCurrency())
+		}
+	}
+	return &CappedTariff{base: base, caps: caps}, nil
+}
+
+// CapsSpec : stable JSON form of the caps of a tariff
+type CapsSpec struct {
+	Daily  *money.Money `json:"daily,omitempty"`
+	Period CapPeriod    `json:"period,omitempty"`
+	Stay   *money.Money `json:"stay,omitempty"`
+}
+
+func (caps Caps) GetSpec() *CapsSpec {
+	spec := &CapsSpec{Period: caps.Period}
+	if !caps.Daily.IsZero() {
+		daily := caps.Daily
+		spec.Daily = &daily
+	}
+	if !caps.Stay.IsZero() {
+		stay := caps.Stay
+		spec.Stay = &stay
+	}
+	return spec
+}
+
+func (capsSpec CapsSpec) Build() Caps {
+	caps := Caps{Period: capsSpec.Period}
+	if capsSpec.Daily != nil {
+		caps.Daily = *capsSpec.Daily
+	}
+	if capsSpec.Stay != nil {
+		caps.Stay = *capsSpec.Stay
+	}
+	return caps
+}
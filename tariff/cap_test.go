@@ -0,0 +1,115 @@
+package tariff
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/hbkkanna/parking/money"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDailyCap(t *testing.T) {
+	capped, err := NewCappedTariff(NewEveryHour(usd(3)), Caps{Daily: usd(30)})
+	if err != nil {
+		t.Fatalf("capped tariff failed %v", err)
+	}
+	inTime := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	if cost := capped.GetCost(stayAt(inTime, 5*time.Hour)); cost != usd(15) {
+		t.Errorf("cap must not apply to short stays, got %v", cost)
+	}
+	// the stay is priced as a whole, 24h capped at 30, 6h at 18
+	stay := stayAt(inTime, 30*time.Hour)
+	items := capped.Itemize(stay)
+	if capped.GetCost(stay) != usd(48) || len(items) != 2 || items[1].Model != DailyCapModel || items[1].Subtotal != usd(-42) {
+		t.Errorf("unexpected daily cap %v", items)
+	}
+	if items[1].Description() != "DailyCap [0s, 24h0m0s)" {
+		t.Errorf("unexpected cap description %s", items[1].Description())
+	}
+}
+
+func TestCalendarDayCap(t *testing.T) {
+	capped, _ := NewCappedTariff(NewEveryHour(usd(3)), Caps{Daily: usd(30), Period: CalendarDay})
+	// 20:00 to 20:00 next day: 4h before midnight at 12, 20h after capped at 30
+	cost := capped.GetCost(stayAt(time.Date(2021, 1, 1, 20, 0, 0, 0, time.UTC), 24*time.Hour))
+	if cost != usd(42) {
+		t.Errorf("unexpected calendar day cap %v", cost)
+	}
+	rolling, _ := NewCappedTariff(NewEveryHour(usd(3)), Caps{Daily: usd(30)})
+	if cost := rolling.GetCost(stayAt(time.Date(2021, 1, 1, 20, 0, 0, 0, time.UTC), 24*time.Hour)); cost != usd(30) {
+		t.Errorf("unexpected rolling cap %v", cost)
+	}
+}
+
+func TestCapNotReached(t *testing.T) {
+	// 10:50 to 00:20 is 14 started hours, the minutes after midnight are not a new hour
+	capped, _ := NewCappedTariff(NewEveryHour(usd(10)), Caps{Daily: usd(1000), Period: CalendarDay})
+	stay := stayAt(time.Date(2021, 1, 1, 10, 50, 0, 0, time.UTC), 13*time.Hour+30*time.Minute)
+	if cost := capped.GetCost(stay); cost != usd(140) || len(capped.Itemize(stay)) != 1 {
+		t.Errorf("cap must not change the price below it, got %v", cost)
+	}
+
+	// the free first hour and the bands apply once to the stay, not every day
+	airport := NewSingleTariffMatcher()
+	airport.Append(NewHourInterval(usd(0), NewTimeConstraint(HrtoMinutes(0), HrtoMinutes(1))))
+	airport.Append(NewHourInterval(usd(40), NewTimeConstraint(HrtoMinutes(1), HrtoMinutes(8))))
+	airport.Append(NewHourInterval(usd(60), NewTimeConstraint(HrtoMinutes(8), HrtoMinutes(24))))
+	airport.Append(NewEveryDay(usd(80), NewTimeConstraint(DaytoMinutes(0), math.MaxFloat64)))
+	stay = stayAt(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC), 25*time.Hour)
+	uncapped := airport.GetCost(stay)
+	capped, _ = NewCappedTariff(airport, Caps{Daily: usd(1000)})
+	if cost := capped.GetCost(stay); cost != uncapped || cost != usd(160) {
+		t.Errorf("expected the uncapped %v, got %v", uncapped, cost)
+	}
+}
+
+func TestStayCap(t *testing.T) {
+	base := NewMultipleTariffMatcher()
+	base.Append(NewEveryDay(usd(80), NewTimeConstraint(0, DaytoMinutes(100))))
+	capped, _ := NewCappedTariff(base, Caps{Daily: usd(60), Stay: usd(150)})
+	inTime := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	if cost := capped.GetCost(stayAt(inTime, 36*time.Hour)); cost != usd(120) {
+		t.Errorf("unexpected daily capped cost %v", cost)
+	}
+	stay := stayAt(inTime, 72*time.Hour)
+	items := capped.Itemize(stay)
+	last := items[len(items)-1]
+	if capped.GetCost(stay) != usd(150) || last.Model != StayCapModel || last.Subtotal != usd(-30) {
+		t.Errorf("unexpected stay cap %v", items)
+	}
+}
+
+func TestCapsRoundTrip(t *testing.T) {
+	base := NewSingleTariffMatcher()
+	base.Append(NewEveryHour(usd(3)))
+	capped, _ := NewCappedTariff(base, Caps{Daily: usd(30), Period: CalendarDay, Stay: usd(200)})
+	spec, err := capped.GetSpec()
+	if err != nil {
+		t.Fatalf("spec failed %v", err)
+	}
+	data, _ := json.Marshal(spec)
+	if string(data) != `{"matcher":"Single","currency":"USD","caps":{"daily":"30.00 USD","period":"CalendarDay","stay":"200.00 USD"},"models":[{"model":"EveryHour","price":"3.00 USD"}]}` {
+		t.Errorf("unexpected schema %s", data)
+	}
+	decoded, err := UnmarshalTariff(data)
+	if err != nil || !reflect.DeepEqual(decoded, capped) {
+		t.Errorf("round trip failed %#v %v", decoded, err)
+	}
+	if err := json.Unmarshal(data, NewSingleTariffMatcher()); !errors.Is(err, ErrInvalidCap) {
+		t.Errorf("expected ErrInvalidCap, got %v", err)
+	}
+}
+
+func TestInvalidCaps(t *testing.T) {
+	if _, err := NewCappedTariff(NewEveryHour(usd(3)), Caps{Daily: usd(-1)}); !errors.Is(err, ErrInvalidCap) {
+		t.Errorf("expected ErrInvalidCap, got %v", err)
+	}
+	if _, err := NewCappedTariff(NewEveryHour(usd(3)), Caps{Daily: usd(1), Period: "Weekly"}); !errors.Is(err, ErrInvalidCap) {
+		t.Errorf("expected ErrInvalidCap, got %v", err)
+	}
+	if _, err := NewCappedTariff(NewEveryHour(usd(3)), Caps{Stay: money.New(100, "EUR")}); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+}
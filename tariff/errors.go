@@ -0,0 +1,40 @@
+package tariff
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnknownModel   = errors.New("unknown tariff model")
+	ErrUnknownMatcher = errors.New("unknown tariff matcher")
+	ErrInvalidRange   = errors.New("invalid time range")
+	ErrNotInRange     = errors.New("stay not in range of the tariff")
+)
+
+// RangeError : time constraint with negative or inverted bounds, matches ErrInvalidRange
+type RangeError struct {
+	Start float64
+	End   float64
+}
+
+func (rangeError *RangeError) Error() string {
+	return fmt.Sprintf("invalid time range start %v minutes, end %v minutes", rangeError.Start, rangeError.End)
+}
+
+func (rangeError *RangeError) Is(target error) bool {
+	return target == ErrInvalidRange
+}
+
+// NotInRangeError : no model of the tariff prices the stay, matches ErrNotInRange
+type NotInRangeError struct {
+	Minutes float64
+}
+
+func (notInRangeError *NotInRangeError) Error() string {
+	return fmt.Sprintf("stay of %s not in range of the tariff", minutesToDuration(notInRangeError.Minutes))
+}
+
+func (notInRangeError *NotInRangeError) Is(target error) bool {
+	return target == ErrNotInRange
+}
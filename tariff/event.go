@@ -0,0 +1,88 @@
+package tariff
+
+import (
+	"fmt"
+	"github.com/hbkkanna/parking/calendar"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"math"
+)
+
+const EventSurchargeModel = "EventSurcharge"
+
+// EventTariff : prices stays overlapping an event of the calendar with the event chain,
+// the base chain when there is none, and adds the surcharge once for every event the stay
+// overlaps. Line items name the event that triggered them, Append adds to the base chain.
+type EventTariff struct {
+	base        Tariff
+	calendar    *calendar.Calendar
+	eventTariff Tariff
+	surcharge   money.Money
+}
+
+func (eventTariff *EventTariff) GetCost(parkingTime slot.ParkingTime) money.Money {
+	return getCost(eventTariff.Itemize(parkingTime), eventTariff.GetCurrency())
+}
+
+func (eventTariff *EventTariff) Itemize(parkingTime slot.ParkingTime) []LineItem {
+	events := eventTariff.calendar.Overlapping(parkingTime.GetInTime(), parkingTime.GetOutTime())
+	if len(events) == 0 {
+		return eventTariff.base.Itemize(parkingTime)
+	}
+	items := eventTariff.base.Itemize(parkingTime)
+	if eventTariff.eventTariff != nil {
+		items = eventTariff.eventTariff.Itemize(parkingTime)
+		for i := range items {
+			items[i].Event = events[0].Name
+		}
+	}
+	if items == nil || eventTariff.surcharge.IsZero() {
+		return items
+	}
+	for _, event := range events {
+		items = append(items, LineItem{Model: EventSurchargeModel, Event: event.Name, End: math.MaxFloat64,
+			Units: 1, Unit: UnitFlat, Price: eventTariff.surcharge, Subtotal: eventTariff.surcharge})
+	}
+	return items
+}
+
+func (eventTariff *EventTariff) Append(calculator ModelCalculator) {
+	eventTariff.base.Append(calculator)
+}
+
+// GetModels returns the models of the base chain
+func (eventTariff *EventTariff) GetModels() []ModelCalculator {
+	return eventTariff.base.GetModels()
+}
+
+func (eventTariff *EventTariff) GetCurrency() string {
+	return eventTariff.base.GetCurrency()
+}
+
+// GetEventTariff returns the chain used during events, nil when events only add the surcharge
+func (eventTariff *EventTariff) GetEventTariff() Tariff {
+	return eventTariff.eventTariff
+}
+
+func (eventTariff *EventTariff) chains() []Tariff {
+	if eventTariff.eventTariff == nil {
+		return []Tariff{eventTariff.base}
+	}
+	return []Tariff{eventTariff.base, eventTariff.eventTariff}
+}
+
+// GetSpec fails, the calendar is not part of the tariff schema
+func (eventTariff *EventTariff) GetSpec() (TariffSpec, error) {
+	return TariffSpec{}, fmt.Errorf("%w: event tariffs depend on a calendar", ErrNotSerializable)
+}
+
+// NewEventTariff wraps the base chain, the event chain may be nil and the surcharge zero
+func NewEventTariff(base Tariff, calendar *calendar.Calendar, eventTariff Tariff, surcharge money.Money) (*EventTariff, error) {
+	if eventTariff != nil && eventTariff.GetCurrency() != base.GetCurrency() {
+		return nil, fmt.Errorf("%w: event tariff in %s, tariff in %s", money.ErrCurrencyMismatch, eventTariff.GetCurrency(), base.GetCurrency())
+	}
+	if !surcharge.IsZero() && surcharge.Currency() != base.GetCurrency() {
+		return nil, fmt.Errorf("%w: surcharge %v, tariff in %s", money.ErrCurrencyMismatch, surcharge, base.GetCurrency())
+	}
+	return &EventTariff{base: base, calendar: calendar, eventTariff: eventTariff, surcharge: surcharge}, nil
+}
@@ -0,0 +1,68 @@
+package tariff
+
+import (
+	"errors"
+	"github.com/hbkkanna/parking/calendar"
+	"github.com/hbkkanna/parking/money"
+	"testing"
+	"time"
+)
+
+func stadiumEvents(t *testing.T) *calendar.Calendar {
+	events, err := calendar.New(
+		calendar.Event{Name: "Cup Final", Start: time.Date(2021, 5, 15, 12, 0, 0, 0, time.UTC), End: time.Date(2021, 5, 15, 20, 0, 0, 0, time.UTC)},
+		calendar.Event{Name: "Concert", Start: time.Date(2021, 5, 15, 19, 0, 0, 0, time.UTC), End: time.Date(2021, 5, 15, 23, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("calendar failed %v", err)
+	}
+	return events
+}
+
+func TestEventTariff(t *testing.T) {
+	base := NewSingleTariffMatcher()
+	base.Append(NewEveryHour(usd(10)))
+	eventChain := NewSingleTariffMatcher()
+	eventChain.Append(NewEveryHour(usd(25)))
+	eventTariff, err := NewEventTariff(base, stadiumEvents(t), eventChain, usd(5))
+	if err != nil {
+		t.Fatalf("event tariff failed %v", err)
+	}
+	// no event
+	if cost := eventTariff.GetCost(stayAt(time.Date(2021, 5, 14, 12, 0, 0, 0, time.UTC), 2*time.Hour)); cost != usd(20) {
+		t.Errorf("base tariff expected, got %v", cost)
+	}
+	// overlaps both events: event chain for 3h and two surcharges
+	stay := stayAt(time.Date(2021, 5, 15, 18, 0, 0, 0, time.UTC), 3*time.Hour)
+	items := eventTariff.Itemize(stay)
+	if len(items) != 3 || items[0].Event != "Cup Final" || items[1].Event != "Cup Final" || items[2].Event != "Concert" {
+		t.Fatalf("unexpected items %v", items)
+	}
+	if items[0].Description() != "EveryHour (Cup Final)" || items[1].Description() != "EventSurcharge (Cup Final)" {
+		t.Errorf("unexpected descriptions %s, %s", items[0].Description(), items[1].Description())
+	}
+	if cost := eventTariff.GetCost(stay); cost != usd(85) {
+		t.Errorf("event tariff expected, got %v", cost)
+	}
+}
+
+func TestEventSurchargeOnly(t *testing.T) {
+	base := NewSingleTariffMatcher()
+	base.Append(NewHourInterval(usd(0), NewTimeConstraint(0, 30)))
+	eventTariff, err := NewEventTariff(base, stadiumEvents(t), nil, usd(5))
+	if err != nil {
+		t.Fatalf("event tariff failed %v", err)
+	}
+	eventTariff.Append(NewEveryHour(usd(10)))
+	if len(base.GetModels()) != 2 || eventTariff.GetCurrency() != "USD" {
+		t.Errorf("append must add to the base chain")
+	}
+	if cost := eventTariff.GetCost(stayAt(time.Date(2021, 5, 15, 13, 0, 0, 0, time.UTC), time.Hour)); cost != usd(15) {
+		t.Errorf("surcharge expected, got %v", cost)
+	}
+	if _, err := eventTariff.GetSpec(); !errors.Is(err, ErrNotSerializable) {
+		t.Errorf("expected ErrNotSerializable, got %v", err)
+	}
+	if _, err := NewEventTariff(base, stadiumEvents(t), nil, money.New(500, "EUR")); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package tariff
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"time"
+)
+
+var ErrInvalidGrace = errors.New("invalid grace period")
+
+const (
+	FreeEntryModel      = "FreeEntry"
+	GraceToleranceModel = "GraceTolerance"
+)
+
+// Grace : stays up to Free cost nothing, minutes up to Tolerance past a whole hour of the
+// stay are not billed, zero durations disable them
+type Grace struct {
+	Free      time.Duration
+	Tolerance time.Duration
+}
+
+// GraceTariff : applies the grace periods before the wrapped tariff. The free window is checked
+// on the actual stay. The tolerance bills the stay as the whole hour it passed, 3h02m as exactly 3h,
+// so the wrapped models match that hour: a range ending at 3h no longer matches and a range
+// starting at 3h does, as ranges are [start, end). Both are zero cost line items on the receipt.
+type GraceTariff struct {
+	base  Tariff
+	grace Grace
+}
+
+func (graceTariff *GraceTariff) GetCost(parkingTime slot.ParkingTime) money.Money {
+	return getCost(graceTariff.Itemize(parkingTime), graceTariff.GetCurrency())
+}
+
+func (graceTariff *GraceTariff) Itemize(parkingTime slot.ParkingTime) []LineItem {
+	stay := parkingTime.GetOutTime().Sub(parkingTime.GetInTime())
+	free := money.New(0, graceTariff.GetCurrency())
+	if graceTariff.grace.Free > 0 && stay <= graceTariff.grace.Free {
+		return []LineItem{newLineItem(FreeEntryModel, NewTimeConstraint(0, graceTariff.grace.Free.Minutes()), 1, UnitFlat, free)}
+	}
+	excess := stay % time.Hour
+	if excess == 0 || excess > graceTariff.grace.Tolerance || stay < time.Hour {
+		return graceTariff.base.Itemize(parkingTime)
+	}
+	billed := slot.NewParkingTime()
+	billed.SetInTime(parkingTime.GetInTime())
+	_ = billed.SetOutTime(parkingTime.GetOutTime().Add(-excess))
+	items := graceTariff.base.Itemize(billed)
+	if items == nil {
+		return nil
+	}
+	tolerance := NewTimeConstraint((stay - excess).Minutes(), stay.Minutes())
+	return append(items, newLineItem(GraceToleranceModel, tolerance, 1, UnitFlat, free))
+}
+
+func (graceTariff *GraceTariff) Append(calculator ModelCalculator) {
+	graceTariff.base.Append(calculator)
+}
+
+// GetModels returns the models of the wrapped tariff
+func (graceTariff *GraceTariff) GetModels() []ModelCalculator {
+	return graceTariff.base.GetModels()
+}
+
+func (graceTariff *GraceTariff) GetCurrency() string {
+	return graceTariff.base.GetCurrency()
+}
+
+func (graceTariff *GraceTariff) GetGrace() Grace {
+	return graceTariff.grace
+}
+
+// GetSpec describes the wrapped tariff with its grace periods
+func (graceTariff *GraceTariff) GetSpec() (TariffSpec, error) {
+	spec, err := graceTariff.base.GetSpec()
+	if err != nil {
+		return TariffSpec{}, err
+	}
+	spec.Grace = graceTariff.grace.GetSpec()
+	return spec, nil
+}
+
+func (graceTariff *GraceTariff) chains() []Tariff {
+	return []Tariff{graceTariff.base}
+}
+
+// NewGraceTariff applies the grace periods to any model or tariff, a model is wrapped in a
+// SingleTariffMatcher. The tolerance has to be shorter than an hour.
+func NewGraceTariff(calculator ModelCalculator, grace Grace) (*GraceTariff, error) {
+	if grace.Free < 0 || grace.Tolerance < 0 || grace.Tolerance >= time.Hour {
+		return nil, fmt.Errorf("%w: free %v, tolerance %v", ErrInvalidGrace, grace.Free, grace.Tolerance)
+	}
+	base, ok := calculator.(Tariff)
+	if !ok {
+		base = NewSingleTariffMatcher()
+		base.Append(calculator)
+	}
+	return &GraceTariff{base: base, grace: grace}, nil
+}
+
+// GraceSpec : stable JSON form of the grace periods, Go durations
+type GraceSpec struct {
+	Free      string `json:"free,omitempty"`
+	Tolerance string `json:"tolerance,omitempty"`
+}
+
+func (grace Grace) GetSpec() *GraceSpec {
+	spec := &GraceSpec{}
+	if grace.Free != 0 {
+		spec.Free = grace.Free.String()
+	}
+	if grace.Tolerance != 0 {
+		spec.Tolerance = grace.Tolerance.String()
+	}
+	return spec
+}
+
+func (graceSpec GraceSpec) Build() (Grace, error) {
+	var grace Grace
+	for _, value := range []struct {
+		text     string
+		duration *time.Duration
+	}{{graceSpec.Free, &grace.Free}, {graceSpec.Tolerance, &grace.Tolerance}} {
+		if value.text == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(value.text)
+		if err != nil {
+			return Grace{}, fmt.Errorf("%w: %v", ErrInvalidGrace, err)
+		}
+		*value.duration = duration
+	}
+	return grace, nil
+}
@@ -0,0 +1,85 @@
+package tariff
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFreeEntry(t *testing.T) {
+	grace, err := NewGraceTariff(NewEveryHour(usd(10)), Grace{Free: 10 * time.Minute})
+	if err != nil {
+		t.Fatalf("grace tariff failed %v", err)
+	}
+	inTime := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	for _, stay := range []time.Duration{0, 10 * time.Minute} {
+		items := grace.Itemize(stayAt(inTime, stay))
+		if grace.GetCost(stayAt(inTime, stay)) != usd(0) || len(items) != 1 || items[0].Model != FreeEntryModel {
+			t.Errorf("stay of %v must be free %v", stay, items)
+		}
+	}
+	if cost := grace.GetCost(stayAt(inTime, 11*time.Minute)); cost != usd(10) {
+		t.Errorf("stay past the free window must be billed from the entry, got %v", cost)
+	}
+	// without a free window a short stay keeps its hourly minimum
+	plain, _ := NewGraceTariff(NewEveryHour(usd(10)), Grace{})
+	if cost := plain.GetCost(stayAt(inTime, time.Minute)); cost != usd(10) {
+		t.Errorf("disabled grace must not change the cost, got %v", cost)
+	}
+}
+
+func TestGraceTolerance(t *testing.T) {
+	grace, _ := NewGraceTariff(NewEveryHour(usd(10)), Grace{Tolerance: 5 * time.Minute})
+	inTime := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	stay := stayAt(inTime, 3*time.Hour+2*time.Minute)
+	items := grace.Itemize(stay)
+	if grace.GetCost(stay) != usd(30) || len(items) != 2 || items[1].Description() != "GraceTolerance [3h0m0s, 3h2m0s)" {
+		t.Errorf("3h02m must bill as 3h %v", items)
+	}
+	if cost := grace.GetCost(stayAt(inTime, 3*time.Hour+6*time.Minute)); cost != usd(40) {
+		t.Errorf("stay past the tolerance must round up, got %v", cost)
+	}
+	if cost := grace.GetCost(stayAt(inTime, 3*time.Minute)); cost != usd(10) {
+		t.Errorf("tolerance must not apply to the first hour, got %v", cost)
+	}
+}
+
+func TestGraceIntervalBoundary(t *testing.T) {
+	base := NewSingleTariffMatcher()
+	base.Append(NewHourInterval(usd(40), NewTimeConstraint(0, HrtoMinutes(8))))
+	base.Append(NewHourInterval(usd(60), NewTimeConstraint(HrtoMinutes(8), HrtoMinutes(24))))
+	grace, _ := NewGraceTariff(base, Grace{Tolerance: 5 * time.Minute})
+	inTime := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	// billed as exactly 8h, which the [8h, 24h) interval matches
+	if cost := grace.GetCost(stayAt(inTime, 8*time.Hour+3*time.Minute)); cost != usd(60) {
+		t.Errorf("tolerance must bill the whole hour, got %v", cost)
+	}
+	if cost := grace.GetCost(stayAt(inTime, 7*time.Hour+59*time.Minute)); cost != usd(40) {
+		t.Errorf("stay before the boundary keeps its interval, got %v", cost)
+	}
+}
+
+func TestGraceRoundTrip(t *testing.T) {
+	data := []byte(`{"matcher":"Single","currency":"USD","caps":{"daily":"30.00 USD","period":"Rolling24h"},"grace":{"free":"10m0s","tolerance":"5m0s"},"models":[{"model":"EveryHour","price":"3.00 USD"}]}`)
+	decoded, err := UnmarshalTariff(data)
+	if err != nil {
+		t.Fatalf("unmarshal failed %v", err)
+	}
+	graceTariff, ok := decoded.(*GraceTariff)
+	if !ok || !reflect.DeepEqual(graceTariff.GetGrace(), Grace{Free: 10 * time.Minute, Tolerance: 5 * time.Minute}) {
+		t.Fatalf("unexpected tariff %#v", decoded)
+	}
+	spec, _ := decoded.GetSpec()
+	encoded, _ := json.Marshal(spec)
+	if string(encoded) != string(data) {
+		t.Errorf("round trip changed the schema %s", encoded)
+	}
+	if _, err := NewGraceTariff(NewEveryHour(usd(1)), Grace{Tolerance: time.Hour}); !errors.Is(err, ErrInvalidGrace) {
+		t.Errorf("expected ErrInvalidGrace, got %v", err)
+	}
+	if _, err := UnmarshalTariff([]byte(`{"matcher":"Single","grace":{"free":"ten"},"models":[]}`)); !errors.Is(err, ErrInvalidGrace) {
+		t.Errorf("expected ErrInvalidGrace, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package tariff
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hbkkanna/parking/money"
+	"math"
+	"time"
+)
+
+var ErrInvalidGranularity = errors.New("invalid billing granularity")
+
+// BlockRounding : how a stay that ends inside a billing block is rounded
+type BlockRounding string
+
+const (
+	Ceil    BlockRounding = "Ceil"
+	Floor   BlockRounding = "Floor"
+	Nearest BlockRounding = "Nearest" // half a block rounds up
+)
+
+// Granularity : billing blocks of duration based models, the price stays per hour or day and is
+// charged pro rata per block, e.g. an hourly price in 15 minute blocks bills 1h20m as 1.5 hours.
+// The zero value bills whole model units rounded up. Stays shorter than the minimum are billed
+// as the minimum.
+type Granularity struct {
+	Increment time.Duration // the model unit when zero
+	Rounding  BlockRounding // Ceil when empty
+	Minimum   time.Duration
+}
+
+// Validate requires whole minute blocks
+func (granularity Granularity) Validate() error {
+	if granularity.Increment < 0 || granularity.Increment%time.Minute != 0 || granularity.Minimum < 0 {
+		return fmt.Errorf("%w: increment %v, minimum %v", ErrInvalidGranularity, granularity.Increment, granularity.Minimum)
+	}
+	switch granularity.Rounding {
+	case "", Ceil, Floor, Nearest:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown rounding %q", ErrInvalidGranularity, granularity.Rounding)
+}
+
+// item bills the minutes in blocks of the unit, unitMinutes long
+func (granularity Granularity) item(model string, constraint TimeConstraint, minutes float64, unit string, unitMinutes float64, price money.Money) LineItem {
+	minutes = math.Max(minutes, granularity.Minimum.Minutes())
+	increment := granularity.Increment.Minutes()
+	if increment == 0 {
+		increment = unitMinutes
+	}
+	blocks := minutes / increment
+	switch granularity.Rounding {
+	case Floor:
+		blocks = math.Floor(blocks)
+	case Nearest:
+		blocks = math.Floor(blocks + 0.5)
+	default:
+		blocks = math.Ceil(blocks)
+	}
+	billed := int64(blocks) * int64(increment)
+	lineItem := newLineItem(model, constraint, float64(billed)/unitMinutes, unit, price)
+	lineItem.Subtotal = price.MulRat(billed, int64(unitMinutes), money.RoundHalfUp)
+	return lineItem
+}
+
+// granular is implemented by the duration based models
+type granular interface {
+	withGranularity(granularity Granularity) ModelCalculator
+}
+
+// WithGranularity returns a copy of the duration based model billed in the blocks of the granularity,
+// flat models are rejected
+func WithGranularity(model ModelCalculator, granularity Granularity) (ModelCalculator, error) {
+	if err := granularity.Validate(); err != nil {
+		return nil, err
+	}
+	granularModel, ok := model.(granular)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not billed by duration", ErrInvalidGranularity, model)
+	}
+	return granularModel.withGranularity(granularity), nil
+}
+
+func (everyHour *EveryHour) withGranularity(granularity Granularity) ModelCalculator {
+	model := *everyHour
+	model.granularity = granularity
+	return &model
+}
+
+func (everyDay *EveryDay) withGranularity(granularity Granularity) ModelCalculator {
+	model := *everyDay
+	model.granularity = granularity
+	return &model
+}
+
+func (everyHourInInterval *EveryHourInInterval) withGranularity(granularity Granularity) ModelCalculator {
+	model := *everyHourInInterval
+	model.granularity = granularity
+	return &model
+}
+
+// GranularitySpec : stable JSON form of the granularity, Go durations
+type GranularitySpec struct {
+	Increment string        `json:"increment,omitempty"`
+	Rounding  BlockRounding `json:"rounding,omitempty"`
+	Minimum   string        `json:"minimum,omitempty"`
+}
+
+func (granularity Granularity) GetSpec() *GranularitySpec {
+	if granularity == (Granularity{}) {
+		return nil
+	}
+	spec := &GranularitySpec{Rounding: granularity.Rounding}
+	if granularity.Increment != 0 {
+		spec.Increment = granularity.Increment.String()
+	}
+	if granularity.Minimum != 0 {
+		spec.Minimum = granularity.Minimum.String()
+	}
+	return spec
+}
+
+func (granularitySpec GranularitySpec) Build() (Granularity, error) {
+	granularity := Granularity{Rounding: granularitySpec.Rounding}
+	for _, value := range []struct {
+		text     string
+		duration *time.Duration
+	}{{granularitySpec.Increment, &granularity.Increment}, {granularitySpec.Minimum, &granularity.Minimum}} {
+		if value.text == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(value.text)
+		if err != nil {
+			return Granularity{}, fmt.Errorf("%w: %v", ErrInvalidGranularity, err)
+		}
+		*value.duration = duration
+	}
+	return granularity, granularity.Validate()
+}
@@ -0,0 +1,76 @@
+package tariff
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGranularity(t *testing.T) {
+	inTime := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	cases := []struct {
+		granularity Granularity
+		stay        time.Duration
+		units       float64
+		cost        float64
+	}{
+		{Granularity{}, 80 * time.Minute, 2, 20},
+		{Granularity{Increment: 15 * time.Minute}, 80 * time.Minute, 1.5, 15},
+		{Granularity{Increment: 15 * time.Minute, Rounding: Floor}, 80 * time.Minute, 1.25, 12.5},
+		{Granularity{Increment: 15 * time.Minute, Rounding: Nearest}, 82 * time.Minute, 1.25, 12.5},
+		{Granularity{Increment: 15 * time.Minute, Rounding: Nearest}, 83 * time.Minute, 1.5, 15},
+		{Granularity{Increment: 15 * time.Minute, Minimum: time.Hour}, 5 * time.Minute, 1, 10},
+		{Granularity{Increment: 20 * time.Minute}, 10 * time.Minute, 1.0 / 3, 3.33},
+	}
+	for _, c := range cases {
+		model, err := WithGranularity(NewEveryHour(usd(10)), c.granularity)
+		if err != nil {
+			t.Fatalf("granularity failed %v", err)
+		}
+		items := Itemize(model, stayAt(inTime, c.stay))
+		if items[0].Units != c.units || model.GetCost(stayAt(inTime, c.stay)) != usd(c.cost) {
+			t.Errorf("%+v for %v: expected %v units %v, got %v", c.granularity, c.stay, c.units, usd(c.cost), items)
+		}
+	}
+}
+
+func TestIntervalGranularity(t *testing.T) {
+	// 15 minute blocks from the start of the interval, daily price in hour blocks
+	hourly, _ := WithGranularity(NewEveryHourInInterval(usd(8), NewTimeConstraint(HrtoMinutes(2), HrtoMinutes(24))), Granularity{Increment: 15 * time.Minute})
+	daily, _ := WithGranularity(NewEveryDay(usd(48), NewTimeConstraint(0, DaytoMinutes(30))), Granularity{Increment: time.Hour})
+	inTime := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	if cost := hourly.GetCost(stayAt(inTime, 2*time.Hour+40*time.Minute)); cost != usd(6) {
+		t.Errorf("unexpected interval cost %v", cost)
+	}
+	if cost := daily.GetCost(stayAt(inTime, 30*time.Hour)); cost != usd(60) {
+		t.Errorf("unexpected daily cost %v", cost)
+	}
+}
+
+func TestGranularityErrors(t *testing.T) {
+	if _, err := WithGranularity(NewHourInterval(usd(1), NewTimeConstraint(0, 60)), Granularity{Increment: 15 * time.Minute}); !errors.Is(err, ErrInvalidGranularity) {
+		t.Errorf("flat models must be rejected, got %v", err)
+	}
+	for _, granularity := range []Granularity{{Increment: 90 * time.Second}, {Rounding: "Up"}, {Minimum: -time.Minute}} {
+		if _, err := WithGranularity(NewEveryHour(usd(1)), granularity); !errors.Is(err, ErrInvalidGranularity) {
+			t.Errorf("expected ErrInvalidGranularity for %+v, got %v", granularity, err)
+		}
+	}
+}
+
+func TestGranularityRoundTrip(t *testing.T) {
+	model, _ := WithGranularity(NewEveryHour(usd(10)), Granularity{Increment: 15 * time.Minute, Rounding: Nearest, Minimum: 30 * time.Minute})
+	data, _ := json.Marshal(model)
+	if string(data) != `{"model":"EveryHour","price":"10.00 USD","granularity":{"increment":"15m0s","rounding":"Nearest","minimum":"30m0s"}}` {
+		t.Errorf("unexpected schema %s", data)
+	}
+	decoded, err := UnmarshalModel(data)
+	if err != nil || !reflect.DeepEqual(decoded, model) {
+		t.Errorf("round trip failed %#v %v", decoded, err)
+	}
+	if _, err := UnmarshalModel([]byte(`{"model":"HourInterval","price":"1 USD","end":"1h","granularity":{"increment":"15m"}}`)); !errors.Is(err, ErrInvalidGranularity) {
+		t.Errorf("expected ErrInvalidGranularity, got %v", err)
+	}
+}
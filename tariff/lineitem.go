@@ -0,0 +1,91 @@
+package tariff
+
+import (
+	"fmt"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"math"
+)
+
+const (
+	UnitHour = "hour"
+	UnitDay  = "day"
+	UnitFlat = "flat"
+)
+
+// LineItem : cost of one model, Units are the billed hours or days after ceiling
+type LineItem struct {
+	Model    string
+	Band     string  // time band of time of day models
+	Event    string  // calendar event that priced the item
+	Start    float64 // matched interval in minutes
+	End      float64
+	Units    float64
+	Unit     string
+	Price    money.Money
+	Subtotal money.Money
+}
+
+func newLineItem(model string, constraint TimeConstraint, units float64, unit string, price money.Money) LineItem {
+	return LineItem{
+		Model:    model,
+		Start:    constraint.start,
+		End:      constraint.end,
+		Units:    units,
+		Unit:     unit,
+		Price:    price,
+		Subtotal: price.Mul(int64(units)),
+	}
+}
+
+// Description names the model, the interval or band it matched and the event that priced it
+func (lineItem LineItem) Description() string {
+	description := lineItem.Model
+	switch {
+	case lineItem.Band != "":
+		description = fmt.Sprintf("%s %s", lineItem.Model, lineItem.Band)
+	case lineItem.Start != 0 || lineItem.End != math.MaxFloat64:
+		description = fmt.Sprintf("%s [%s, %s)", lineItem.Model, formatMinutes(lineItem.Start), formatMinutes(lineItem.End))
+	}
+	if lineItem.Event != "" {
+		return fmt.Sprintf("%s (%s)", description, lineItem.Event)
+	}
+	return description
+}
+
+func (lineItem LineItem) String() string {
+	if lineItem.Unit == UnitFlat {
+		return fmt.Sprintf("%s: %v", lineItem.Description(), lineItem.Subtotal)
+	}
+	return fmt.Sprintf("%s: %v %s x %v = %v", lineItem.Description(), lineItem.Units, lineItem.Unit, lineItem.Price, lineItem.Subtotal)
+}
+
+// Itemizer explains a cost as line items, nil when the stay is not in range
+type Itemizer interface {
+	Itemize(parkingTime slot.ParkingTime) []LineItem
+}
+
+// Itemize returns the line items of any calculator, calculators that can not explain
+// their cost give a single line with the total
+func Itemize(calculator ModelCalculator, parkingTime slot.ParkingTime) []LineItem {
+	if itemizer, ok := calculator.(Itemizer); ok {
+		return itemizer.Itemize(parkingTime)
+	}
+	cost := calculator.GetCost(parkingTime)
+	if !IsInRange(cost) {
+		return nil
+	}
+	return []LineItem{{Model: fmt.Sprintf("%T", calculator), End: math.MaxFloat64, Units: 1, Unit: UnitFlat, Price: cost, Subtotal: cost}}
+}
+
+// getCost sums the line items, NOTINRANGE when nothing matched
+func getCost(items []LineItem, currency string) money.Money {
+	if items == nil {
+		return money.New(NOTINRANGE, currency)
+	}
+	var sum money.Money
+	for _, item := range items {
+		sum = sum.Add(item.Subtotal)
+	}
+	return sum
+}
@@ -0,0 +1,60 @@
+package tariff
+
+import (
+	"fmt"
+	"github.com/hbkkanna/parking/money"
+)
+
+const (
+	EveryHourModel            = "EveryHour"
+	EveryDayModel             = "EveryDay"
+	HourIntervalModel         = "HourInterval"
+	PreviousHourIntervalModel = "PreviousHourInterval"
+	EveryHourInIntervalModel  = "EveryHourInInterval"
+	TimeOfDayModel            = "TimeOfDay"
+	CompositeModel            = "Composite"
+
+	SingleMatcher   = "Single"
+	MultipleMatcher = "Multiple"
+	CheapestMatcher = "Cheapest"
+	MaxMatcher      = "Max"
+)
+
+// NewModel creates the tariff model by name, EveryHour ignores the constraint,
+// TimeOfDay models are built from their bands with NewTimeOfDay or ModelSpec.Build,
+// Composite models are nested matchers appended to the matcher
+func NewModel(name string, price money.Money, constraint TimeConstraint) (ModelCalculator, error) {
+	if name != EveryHourModel {
+		if err := constraint.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	switch name {
+	case EveryHourModel:
+		return NewEveryHour(price), nil
+	case EveryDayModel:
+		return NewEveryDay(price, constraint), nil
+	case HourIntervalModel:
+		return NewHourInterval(price, constraint), nil
+	case PreviousHourIntervalModel:
+		return NewPreviousHourInterval(price, constraint), nil
+	case EveryHourInIntervalModel:
+		return NewEveryHourInInterval(price, constraint), nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownModel, name)
+}
+
+// NewMatcher creates an empty tariff matcher by name
+func NewMatcher(name string) (Tariff, error) {
+	switch name {
+	case SingleMatcher:
+		return NewSingleTariffMatcher(), nil
+	case MultipleMatcher:
+		return NewMultipleTariffMatcher(), nil
+	case CheapestMatcher:
+		return NewCheapestTariffMatcher(), nil
+	case MaxMatcher:
+		return NewMaxTariffMatcher(), nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownMatcher, name)
+}
@@ -0,0 +1,44 @@
+package tariff
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNewModel(t *testing.T) {
+	names := []string{EveryHourModel, EveryDayModel, HourIntervalModel, PreviousHourIntervalModel, EveryHourInIntervalModel}
+	for _, name := range names {
+		if _, err := NewModel(name, usd(10), NewTimeConstraint(0, math.MaxFloat64)); err != nil {
+			t.Errorf("model %s failed %v", name, err)
+		}
+	}
+	if _, err := NewModel("Monthly", usd(10), NewTimeConstraint(0, 60)); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("expected ErrUnknownModel, got %v", err)
+	}
+
+	// inverted range
+	_, err := NewModel(HourIntervalModel, usd(10), NewTimeConstraint(HrtoMinutes(8), HrtoMinutes(4)))
+	var rangeError *RangeError
+	if !errors.Is(err, ErrInvalidRange) || !errors.As(err, &rangeError) || rangeError.Start != 480 {
+		t.Errorf("expected RangeError, got %v", err)
+	}
+}
+
+func TestNewMatcher(t *testing.T) {
+	if tariff, err := NewMatcher(SingleMatcher); err != nil || tariff == nil {
+		t.Errorf("single matcher failed %v", err)
+	}
+	if tariff, err := NewMatcher(MultipleMatcher); err != nil || tariff == nil {
+		t.Errorf("multiple matcher failed %v", err)
+	}
+	if tariff, err := NewMatcher(CheapestMatcher); err != nil || tariff == nil {
+		t.Errorf("cheapest matcher failed %v", err)
+	}
+	if tariff, err := NewMatcher(MaxMatcher); err != nil || tariff == nil {
+		t.Errorf("max matcher failed %v", err)
+	}
+	if _, err := NewMatcher("Priority"); !errors.Is(err, ErrUnknownMatcher) {
+		t.Errorf("expected ErrUnknownMatcher, got %v", err)
+	}
+}
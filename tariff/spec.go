@@ -0,0 +1,411 @@
+package tariff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hbkkanna/parking/money"
+	"math"
+	"time"
+)
+
+var ErrNotSerializable = errors.New("tariff model is not serializable")
+
+// ModelSpec : stable JSON form of a tariff model, ranges are Go durations,
+// a missing start is 0 and a missing end is unbounded. Bands are only used by TimeOfDay,
+// granularity only by the duration based models. A Composite model is the nested tariff,
+// its price is zero in the currency of that tariff and may be omitted.
+type ModelSpec struct {
+	Model       string           `json:"model"`
+	Price       money.Money      `json:"price"`
+	Start       string           `json:"start,omitempty"`
+	End         string           `json:"end,omitempty"`
+	Bands       []BandSpec       `json:"bands,omitempty"`
+	Granularity *GranularitySpec `json:"granularity,omitempty"`
+	Tariff      *TariffSpec      `json:"tariff,omitempty"`
+}
+
+// TariffSpec : stable JSON form of a tariff matcher and its ordered models,
+// every model has to be priced in the declared currency. Caps wrap the matcher in a CappedTariff,
+// grace periods wrap the capped tariff in a GraceTariff.
+type TariffSpec struct {
+	Matcher  string      `json:"matcher"`
+	Currency string      `json:"currency,omitempty"`
+	Caps     *CapsSpec   `json:"caps,omitempty"`
+	Grace    *GraceSpec  `json:"grace,omitempty"`
+	Models   []ModelSpec `json:"models"`
+}
+
+// Specifier is implemented by models and matchers that can describe themselves
+type Specifier interface {
+	GetSpec() ModelSpec
+}
+
+func newModelSpec(model string, price money.Money, constraint TimeConstraint) ModelSpec {
+	spec := ModelSpec{Model: model, Price: price}
+	if constraint.start != 0 {
+		spec.Start = minutesToDuration(constraint.start)
+	}
+	if constraint.end != math.MaxFloat64 {
+		spec.End = minutesToDuration(constraint.end)
+	}
+	return spec
+}
+
+func minutesToDuration(minutes float64) string {
+	return time.Duration(minutes * float64(time.Minute)).String()
+}
+
+func durationToMinutes(value string, missing float64) (float64, error) {
+	if value == "" {
+		return missing, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	return duration.Minutes(), nil
+}
+
+// GetConstraint returns the range of the spec in minutes
+func (modelSpec ModelSpec) GetConstraint() (TimeConstraint, error) {
+	start, err := durationToMinutes(modelSpec.Start, 0)
+	if err != nil {
+		return TimeConstraint{}, err
+	}
+	end, err := durationToMinutes(modelSpec.End, math.MaxFloat64)
+	if err != nil {
+		return TimeConstraint{}, err
+	}
+	return NewTimeConstraint(start, end), nil
+}
+
+func (modelSpec ModelSpec) Build() (ModelCalculator, error) {
+	if modelSpec.Model == CompositeModel {
+		if modelSpec.Tariff == nil {
+			return nil, fmt.Errorf("%w: %s model without a tariff", ErrUnknownMatcher, CompositeModel)
+		}
+		return modelSpec.Tariff.Build()
+	}
+	if modelSpec.Model == TimeOfDayModel {
+		var bands []Band
+		for i, bandSpec := range modelSpec.Bands {
+			band, err := bandSpec.Build()
+			if err != nil {
+				return nil, fmt.Errorf("band %d: %w", i, err)
+			}
+			bands = append(bands, band)
+		}
+		return NewTimeOfDay(modelSpec.Price, bands...)
+	}
+	constraint, err := modelSpec.GetConstraint()
+	if err != nil {
+		return nil, err
+	}
+	model, err := NewModel(modelSpec.Model, modelSpec.Price, constraint)
+	if err != nil || modelSpec.Granularity == nil {
+		return model, err
+	}
+	granularity, err := modelSpec.Granularity.Build()
+	if err != nil {
+		return nil, err
+	}
+	return WithGranularity(model, granularity)
+}
+
+func (tariffSpec TariffSpec) Build() (Tariff, error) {
+	tariff, err := NewMatcher(tariffSpec.Matcher)
+	if err != nil {
+		return nil, err
+	}
+	for i, modelSpec := range tariffSpec.Models {
+		model, err := modelSpec.Build()
+		if err != nil {
+			return nil, fmt.Errorf("model %d: %w", i, err)
+		}
+		if currency, ok := getModelCurrency(model); ok && tariffSpec.Currency != "" && currency != tariffSpec.Currency {
+			return nil, fmt.Errorf("model %d: %w: priced in %s, tariff currency %s", i, money.ErrCurrencyMismatch, currency, tariffSpec.Currency)
+		}
+		tariff.Append(model)
+	}
+	return tariffSpec.wrap(tariff)
+}
+
+// wrap applies the caps and grace periods of the spec to the matcher
+func (tariffSpec TariffSpec) wrap(tariff Tariff) (Tariff, error) {
+	if tariffSpec.Caps != nil {
+		capped, err := NewCappedTariff(tariff, tariffSpec.Caps.Build())
+		if err != nil {
+			return nil, err
+		}
+		tariff = capped
+	}
+	if tariffSpec.Grace != nil {
+		grace, err := tariffSpec.Grace.Build()
+		if err != nil {
+			return nil, err
+		}
+		return NewGraceTariff(tariff, grace)
+	}
+	return tariff, nil
+}
+
+// UnmarshalModel decodes any model from its JSON spec
+func UnmarshalModel(data []byte) (ModelCalculator, error) {
+	var modelSpec ModelSpec
+	if err := json.Unmarshal(data, &modelSpec); err != nil {
+		return nil, err
+	}
+	return modelSpec.Build()
+}
+
+// UnmarshalTariff decodes any matcher with its models from its JSON spec
+func UnmarshalTariff(data []byte) (Tariff, error) {
+	var tariffSpec TariffSpec
+	if err := json.Unmarshal(data, &tariffSpec); err != nil {
+		return nil, err
+	}
+	return tariffSpec.Build()
+}
+
+// unmarshalModel decodes the spec into the model, the spec must name the same model
+func unmarshalModel(data []byte, name string) (ModelCalculator, error) {
+	var modelSpec ModelSpec
+	if err := json.Unmarshal(data, &modelSpec); err != nil {
+		return nil, err
+	}
+	if modelSpec.Model != name {
+		return nil, fmt.Errorf("%w %q, expected %q", ErrUnknownModel, modelSpec.Model, name)
+	}
+	return modelSpec.Build()
+}
+
+func (everyHour *EveryHour) GetSpec() ModelSpec {
+	return ModelSpec{Model: EveryHourModel, Price: everyHour.price, Granularity: everyHour.granularity.GetSpec()}
+}
+
+func (everyHour *EveryHour) MarshalJSON() ([]byte, error) {
+	return json.Marshal(everyHour.GetSpec())
+}
+
+func (everyHour *EveryHour) UnmarshalJSON(data []byte) error {
+	model, err := unmarshalModel(data, EveryHourModel)
+	if err != nil {
+		return err
+	}
+	*everyHour = *model.(*EveryHour)
+	return nil
+}
+
+func (everyDay *EveryDay) GetSpec() ModelSpec {
+	spec := newModelSpec(EveryDayModel, everyDay.price, everyDay.TimeConstraint)
+	spec.Granularity = everyDay.granularity.GetSpec()
+	return spec
+}
+
+func (everyDay *EveryDay) MarshalJSON() ([]byte, error) {
+	return json.Marshal(everyDay.GetSpec())
+}
+
+func (everyDay *EveryDay) UnmarshalJSON(data []byte) error {
+	model, err := unmarshalModel(data, EveryDayModel)
+	if err != nil {
+		return err
+	}
+	*everyDay = *model.(*EveryDay)
+	return nil
+}
+
+func (hourInterval *HourInterval) GetSpec() ModelSpec {
+	return newModelSpec(HourIntervalModel, hourInterval.price, hourInterval.TimeConstraint)
+}
+
+func (hourInterval *HourInterval) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hourInterval.GetSpec())
+}
+
+func (hourInterval *HourInterval) UnmarshalJSON(data []byte) error {
+	model, err := unmarshalModel(data, HourIntervalModel)
+	if err != nil {
+		return err
+	}
+	*hourInterval = *model.(*HourInterval)
+	return nil
+}
+
+func (previousHourInterval *PreviousHourInterval) GetSpec() ModelSpec {
+	return newModelSpec(PreviousHourIntervalModel, previousHourInterval.price, previousHourInterval.TimeConstraint)
+}
+
+func (previousHourInterval *PreviousHourInterval) MarshalJSON() ([]byte, error) {
+	return json.Marshal(previousHourInterval.GetSpec())
+}
+
+func (previousHourInterval *PreviousHourInterval) UnmarshalJSON(data []byte) error {
+	model, err := unmarshalModel(data, PreviousHourIntervalModel)
+	if err != nil {
+		return err
+	}
+	*previousHourInterval = *model.(*PreviousHourInterval)
+	return nil
+}
+
+func (everyHourInInterval *EveryHourInInterval) GetSpec() ModelSpec {
+	spec := newModelSpec(EveryHourInIntervalModel, everyHourInInterval.price, everyHourInInterval.TimeConstraint)
+	spec.Granularity = everyHourInInterval.granularity.GetSpec()
+	return spec
+}
+
+func (everyHourInInterval *EveryHourInInterval) MarshalJSON() ([]byte, error) {
+	return json.Marshal(everyHourInInterval.GetSpec())
+}
+
+func (everyHourInInterval *EveryHourInInterval) UnmarshalJSON(data []byte) error {
+	model, err := unmarshalModel(data, EveryHourInIntervalModel)
+	if err != nil {
+		return err
+	}
+	*everyHourInInterval = *model.(*EveryHourInInterval)
+	return nil
+}
+
+func (timeOfDay *TimeOfDay) GetSpec() ModelSpec {
+	spec := ModelSpec{Model: TimeOfDayModel, Price: timeOfDay.price}
+	for _, band := range timeOfDay.bands {
+		spec.Bands = append(spec.Bands, band.GetSpec())
+	}
+	return spec
+}
+
+func (timeOfDay *TimeOfDay) MarshalJSON() ([]byte, error) {
+	return json.Marshal(timeOfDay.GetSpec())
+}
+
+func (timeOfDay *TimeOfDay) UnmarshalJSON(data []byte) error {
+	model, err := unmarshalModel(data, TimeOfDayModel)
+	if err != nil {
+		return err
+	}
+	*timeOfDay = *model.(*TimeOfDay)
+	return nil
+}
+
+func getModelCurrency(model ModelCalculator) (string, bool) {
+	if tariff, ok := model.(Tariff); ok {
+		return tariff.GetCurrency(), tariff.GetCurrency() != ""
+	}
+	specifier, ok := model.(Specifier)
+	if !ok {
+		return "", false
+	}
+	return specifier.GetSpec().Price.Currency(), true
+}
+
+// getTariffSpec describes the matcher, every model has to be a Specifier
+func getTariffSpec(matcher string, baseTariff *BaseTariff) (TariffSpec, error) {
+	tariffSpec := TariffSpec{Matcher: matcher, Currency: baseTariff.GetCurrency(), Models: []ModelSpec{}}
+	for i, model := range baseTariff.orderedTarrif {
+		if nested, ok := model.(Tariff); ok {
+			nestedSpec, err := nested.GetSpec()
+			if err != nil {
+				return TariffSpec{}, fmt.Errorf("model %d: %w", i, err)
+			}
+			tariffSpec.Models = append(tariffSpec.Models, ModelSpec{Model: CompositeModel,
+				Price: money.New(0, nested.GetCurrency()), Tariff: &nestedSpec})
+			continue
+		}
+		specifier, ok := model.(Specifier)
+		if !ok {
+			return TariffSpec{}, fmt.Errorf("%w: model %d %T", ErrNotSerializable, i, model)
+		}
+		tariffSpec.Models = append(tariffSpec.Models, specifier.GetSpec())
+	}
+	return tariffSpec, nil
+}
+
+// unmarshalTariff decodes the spec into the matcher models, the spec must name the same matcher
+func unmarshalTariff(data []byte, matcher string, baseTariff *BaseTariff) error {
+	var tariffSpec TariffSpec
+	if err := json.Unmarshal(data, &tariffSpec); err != nil {
+		return err
+	}
+	if tariffSpec.Matcher != matcher {
+		return fmt.Errorf("%w %q, expected %q", ErrUnknownMatcher, tariffSpec.Matcher, matcher)
+	}
+	if tariffSpec.Caps != nil {
+		return fmt.Errorf("%w: %s matcher can not hold caps, use UnmarshalTariff", ErrInvalidCap, matcher)
+	}
+	if tariffSpec.Grace != nil {
+		return fmt.Errorf("%w: %s matcher can not hold grace periods, use UnmarshalTariff", ErrInvalidGrace, matcher)
+	}
+	tariff, err := tariffSpec.Build()
+	if err != nil {
+		return err
+	}
+	baseTariff.orderedTarrif = tariff.GetModels()
+	return nil
+}
+
+func (singleTariffMatcher *SingleTariffMatcher) GetSpec() (TariffSpec, error) {
+	return getTariffSpec(SingleMatcher, &singleTariffMatcher.BaseTariff)
+}
+
+func (singleTariffMatcher *SingleTariffMatcher) MarshalJSON() ([]byte, error) {
+	tariffSpec, err := singleTariffMatcher.GetSpec()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(tariffSpec)
+}
+
+func (singleTariffMatcher *SingleTariffMatcher) UnmarshalJSON(data []byte) error {
+	return unmarshalTariff(data, SingleMatcher, &singleTariffMatcher.BaseTariff)
+}
+
+func (multipleTariffMatcher *MultipleTariffMatcher) GetSpec() (TariffSpec, error) {
+	return getTariffSpec(MultipleMatcher, &multipleTariffMatcher.BaseTariff)
+}
+
+func (multipleTariffMatcher *MultipleTariffMatcher) MarshalJSON() ([]byte, error) {
+	tariffSpec, err := multipleTariffMatcher.GetSpec()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(tariffSpec)
+}
+
+func (multipleTariffMatcher *MultipleTariffMatcher) UnmarshalJSON(data []byte) error {
+	return unmarshalTariff(data, MultipleMatcher, &multipleTariffMatcher.BaseTariff)
+}
+
+func (cheapestTariffMatcher *CheapestTariffMatcher) GetSpec() (TariffSpec, error) {
+	return getTariffSpec(CheapestMatcher, &cheapestTariffMatcher.BaseTariff)
+}
+
+func (cheapestTariffMatcher *CheapestTariffMatcher) MarshalJSON() ([]byte, error) {
+	tariffSpec, err := cheapestTariffMatcher.GetSpec()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(tariffSpec)
+}
+
+func (cheapestTariffMatcher *CheapestTariffMatcher) UnmarshalJSON(data []byte) error {
+	return unmarshalTariff(data, CheapestMatcher, &cheapestTariffMatcher.BaseTariff)
+}
+
+func (maxTariffMatcher *MaxTariffMatcher) GetSpec() (TariffSpec, error) {
+	return getTariffSpec(MaxMatcher, &maxTariffMatcher.BaseTariff)
+}
+
+func (maxTariffMatcher *MaxTariffMatcher) MarshalJSON() ([]byte, error) {
+	tariffSpec, err := maxTariffMatcher.GetSpec()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(tariffSpec)
+}
+
+func (maxTariffMatcher *MaxTariffMatcher) UnmarshalJSON(data []byte) error {
+	return unmarshalTariff(data, MaxMatcher, &maxTariffMatcher.BaseTariff)
+}
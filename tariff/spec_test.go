@@ -0,0 +1,167 @@
+package tariff
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestModelRoundTrip(t *testing.T) {
+	models := []ModelCalculator{
+		NewEveryHour(usd(10)),
+		NewEveryDay(usd(80), NewTimeConstraint(DaytoMinutes(0), math.MaxFloat64)),
+		NewHourInterval(usd(40), NewTimeConstraint(HrtoMinutes(1), HrtoMinutes(8))),
+		NewPreviousHourInterval(usd(60), NewTimeConstraint(HrtoMinutes(4), HrtoMinutes(12))),
+		NewEveryHourInInterval(usd(100), NewTimeConstraint(HrtoMinutes(12), math.MaxFloat64)),
+		NewHourInterval(usd(5), NewTimeConstraint(30, 90.5)),
+		officeTariff(t),
+	}
+	newTicket := slot.NewTicket(1, slot.NewVehicleSlot(slot.Vehicles[slot.SCOOTER], 2))
+	cur := time.Now()
+	newTicket.SetInTime(cur)
+	newTicket.SetOutTime(cur.Add(time.Hour * 5))
+
+	for _, model := range models {
+		data, err := json.Marshal(model)
+		if err != nil {
+			t.Fatalf("marshal %T failed %v", model, err)
+		}
+		decoded, err := UnmarshalModel(data)
+		if err != nil {
+			t.Fatalf("unmarshal %s failed %v", data, err)
+		}
+		if !reflect.DeepEqual(decoded, model) {
+			t.Errorf("round trip of %s failed, got %#v", data, decoded)
+		}
+		if decoded.GetCost(newTicket) != model.GetCost(newTicket) {
+			t.Errorf("round trip of %s changed the cost", data)
+		}
+
+		// decode into the concrete type
+		typed := reflect.New(reflect.TypeOf(model).Elem()).Interface()
+		if err := json.Unmarshal(data, typed); err != nil || !reflect.DeepEqual(typed, model) {
+			t.Errorf("typed round trip of %s failed %v", data, err)
+		}
+	}
+}
+
+func TestModelSchema(t *testing.T) {
+	data, _ := json.Marshal(NewHourInterval(usd(40), NewTimeConstraint(HrtoMinutes(1), HrtoMinutes(8))))
+	if string(data) != `{"model":"HourInterval","price":"40.00 USD","start":"1h0m0s","end":"8h0m0s"}` {
+		t.Errorf("unexpected schema %s", data)
+	}
+	data, _ = json.Marshal(NewEveryDay(usd(80), NewTimeConstraint(DaytoMinutes(0), math.MaxFloat64)))
+	if string(data) != `{"model":"EveryDay","price":"80.00 USD"}` {
+		t.Errorf("unexpected schema %s", data)
+	}
+
+	var everyHour EveryHour
+	err := json.Unmarshal([]byte(`{"model":"EveryDay","price":"80.00 USD"}`), &everyHour)
+	if !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("expected ErrUnknownModel, got %v", err)
+	}
+}
+
+func TestTimeOfDaySchema(t *testing.T) {
+	model, _ := NewTimeOfDay(usd(1), NewBand("Night", 18*time.Hour, 8*time.Hour, usd(3), time.Friday, time.Saturday))
+	data, _ := json.Marshal(model)
+	if string(data) != `{"model":"TimeOfDay","price":"1.00 USD","bands":[{"name":"Night","from":"18:00","to":"08:00","days":["Fri","Sat"],"price":"3.00 USD"}]}` {
+		t.Errorf("unexpected schema %s", data)
+	}
+}
+
+func TestTariffRoundTrip(t *testing.T) {
+	tariffs := []Tariff{NewSingleTariffMatcher(), NewMultipleTariffMatcher(), NewCheapestTariffMatcher(), NewMaxTariffMatcher()}
+	for _, tariff := range tariffs {
+		tariff.Append(NewPreviousHourInterval(usd(30), NewTimeConstraint(HrtoMinutes(0), HrtoMinutes(4))))
+		tariff.Append(NewPreviousHourInterval(usd(60), NewTimeConstraint(HrtoMinutes(4), HrtoMinutes(12))))
+		tariff.Append(NewEveryHourInInterval(usd(100), NewTimeConstraint(HrtoMinutes(12), math.MaxFloat64)))
+
+		data, err := json.Marshal(tariff)
+		if err != nil {
+			t.Fatalf("marshal failed %v", err)
+		}
+		decoded, err := UnmarshalTariff(data)
+		if err != nil {
+			t.Fatalf("unmarshal %s failed %v", data, err)
+		}
+		if !reflect.DeepEqual(decoded, tariff) {
+			t.Errorf("round trip of %s failed", data)
+		}
+		again, _ := json.Marshal(decoded)
+		if string(again) != string(data) {
+			t.Errorf("schema not stable %s, %s", data, again)
+		}
+	}
+
+	empty, _ := json.Marshal(NewSingleTariffMatcher())
+	if string(empty) != `{"matcher":"Single","models":[]}` {
+		t.Errorf("unexpected schema %s", empty)
+	}
+	var multiple MultipleTariffMatcher
+	if err := json.Unmarshal(empty, &multiple); !errors.Is(err, ErrUnknownMatcher) {
+		t.Errorf("expected ErrUnknownMatcher, got %v", err)
+	}
+}
+
+func TestCompositeRoundTrip(t *testing.T) {
+	chain := NewSingleTariffMatcher()
+	chain.Append(NewHourInterval(usd(10), NewTimeConstraint(0, HrtoMinutes(2))))
+	chain.Append(NewEveryHourInInterval(usd(8), NewTimeConstraint(HrtoMinutes(2), math.MaxFloat64)))
+	composite := NewMultipleTariffMatcher()
+	composite.Append(chain)
+	composite.Append(NewHourInterval(usd(3), NewTimeConstraint(0, math.MaxFloat64)))
+
+	data, err := json.Marshal(composite)
+	if err != nil {
+		t.Fatalf("marshal failed %v", err)
+	}
+	decoded, err := UnmarshalTariff(data)
+	if err != nil {
+		t.Fatalf("unmarshal %s failed %v", data, err)
+	}
+	if !reflect.DeepEqual(decoded, composite) {
+		t.Errorf("round trip of %s failed", data)
+	}
+
+	// the price of a composite model is optional, the nested currency must match
+	data = []byte(`{"matcher":"Max","currency":"EUR","models":[{"model":"Composite","tariff":{"matcher":"Single","models":[{"model":"EveryHour","price":"2.00 USD"}]}}]}`)
+	if _, err := UnmarshalTariff(data); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	data = []byte(`{"matcher":"Max","models":[{"model":"Composite"}]}`)
+	if _, err := UnmarshalTariff(data); !errors.Is(err, ErrUnknownMatcher) {
+		t.Errorf("expected ErrUnknownMatcher, got %v", err)
+	}
+}
+
+type customModel struct{}
+
+func (model customModel) GetCost(parkingTime slot.ParkingTime) money.Money {
+	return usd(1)
+}
+
+func TestTariffNotSerializable(t *testing.T) {
+	tariff := NewSingleTariffMatcher()
+	tariff.Append(customModel{})
+	if _, err := json.Marshal(tariff); !errors.Is(err, ErrNotSerializable) {
+		t.Errorf("expected ErrNotSerializable, got %v", err)
+	}
+}
+
+func TestTariffSpecCurrency(t *testing.T) {
+	data := []byte(`{"matcher":"Single","currency":"EUR","models":[{"model":"EveryHour","price":"10.00 USD"}]}`)
+	if _, err := UnmarshalTariff(data); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	data = []byte(`{"matcher":"Single","currency":"EUR","models":[{"model":"EveryHour","price":"10.00 EUR"}]}`)
+	tariff, err := UnmarshalTariff(data)
+	if err != nil || tariff.GetCurrency() != "EUR" {
+		t.Errorf("unmarshal failed %v", err)
+	}
+}
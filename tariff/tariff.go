@@ -1,14 +1,30 @@
 package tariff
 
 import (
+	"github.com/hbkkanna/parking/money"
 	"github.com/hbkkanna/parking/slot"
 	"math"
 )
 
+// NOTINRANGE : amount in minor units returned as cost when the stay is not in the range of the model
 const NOTINRANGE = -1
 
 type ModelCalculator interface {
-	GetCost(parkingTime slot.ParkingTime) float64
+	GetCost(parkingTime slot.ParkingTime) money.Money
+}
+
+// IsInRange reports whether the cost is not the NOTINRANGE sentinel
+func IsInRange(cost money.Money) bool {
+	return cost.Amount() != NOTINRANGE
+}
+
+// Price returns the cost of the stay, a NotInRangeError when no model of the tariff prices it
+func Price(calculator ModelCalculator, parkingTime slot.ParkingTime) (money.Money, error) {
+	cost := calculator.GetCost(parkingTime)
+	if !IsInRange(cost) {
+		return money.Money{}, &NotInRangeError{Minutes: parkingTime.CalculateMinutes()}
+	}
+	return cost, nil
 }
 
 type TimeConstraint struct {
@@ -23,6 +39,22 @@ func NewTimeConstraint(start float64, end float64) TimeConstraint {
 	}
 }
 
+// Validate reports negative and inverted bounds
+func (timeConstraint *TimeConstraint) Validate() error {
+	if timeConstraint.start < 0 || timeConstraint.end <= timeConstraint.start {
+		return &RangeError{Start: timeConstraint.start, End: timeConstraint.end}
+	}
+	return nil
+}
+
+func (timeConstraint *TimeConstraint) GetStart() float64 {
+	return timeConstraint.start
+}
+
+func (timeConstraint *TimeConstraint) GetEnd() float64 {
+	return timeConstraint.end
+}
+
 func (timeConstraint *TimeConstraint) isInRange(mintVal float64) bool {
 	if mintVal >= timeConstraint.start && mintVal < timeConstraint.end {
 		return true
@@ -39,52 +71,66 @@ func (timeConstraint *TimeConstraint) isGreater(mintVal float64) bool {
 
 // EveryHour Model : hourly  price
 type EveryHour struct {
-	price float64
+	price       money.Money
+	granularity Granularity
 }
 
-func (everyHour *EveryHour) GetCost(parkingTime slot.ParkingTime) float64 {
-	hours := parkingTime.CalculateHours()
-	return math.Ceil(hours) * everyHour.price
+func (everyHour *EveryHour) GetCost(parkingTime slot.ParkingTime) money.Money {
+	return getCost(everyHour.Itemize(parkingTime), everyHour.price.Currency())
 }
 
-func NewEveryHour(price float64) ModelCalculator {
+func (everyHour *EveryHour) Itemize(parkingTime slot.ParkingTime) []LineItem {
+	return []LineItem{everyHour.granularity.item(EveryHourModel, NewTimeConstraint(0, math.MaxFloat64),
+		parkingTime.CalculateMinutes(), UnitHour, HrtoMinutes(1), everyHour.price)}
+}
+
+func NewEveryHour(price money.Money) ModelCalculator {
 	return &EveryHour{price: price}
 }
 
 // EveryDay Model : Daily  price
 type EveryDay struct {
-	price float64
+	price       money.Money
+	granularity Granularity
 	TimeConstraint
 }
 
-func (everyDay *EveryDay) GetCost(parkingTime slot.ParkingTime) float64 {
+func (everyDay *EveryDay) GetCost(parkingTime slot.ParkingTime) money.Money {
+	return getCost(everyDay.Itemize(parkingTime), everyDay.price.Currency())
+}
+
+func (everyDay *EveryDay) Itemize(parkingTime slot.ParkingTime) []LineItem {
 	mins := parkingTime.CalculateMinutes()
 	if everyDay.isInRange(mins) {
 		minutesOffSet := mins - everyDay.start
-		return math.Ceil(MintoDays(minutesOffSet)) * everyDay.price
+		return []LineItem{everyDay.granularity.item(EveryDayModel, everyDay.TimeConstraint, minutesOffSet, UnitDay, DaytoMinutes(1), everyDay.price)}
 	}
-	return NOTINRANGE
+	return nil
 }
 
-func NewEveryDay(price float64, constraint TimeConstraint) ModelCalculator {
+func NewEveryDay(price money.Money, constraint TimeConstraint) ModelCalculator {
 	return &EveryDay{price: price, TimeConstraint: constraint}
 }
 
 // HourInterval Model :  fixed price for hour range
 type HourInterval struct {
 	TimeConstraint
-	price float64
+	price money.Money
 }
 
-func (hourInterval *HourInterval) GetCost(parkingTime slot.ParkingTime) float64 {
+func (hourInterval *HourInterval) GetCost(parkingTime slot.ParkingTime) money.Money {
+	return getCost(hourInterval.Itemize(parkingTime), hourInterval.price.Currency())
+}
+
+func (hourInterval *HourInterval) Itemize(parkingTime slot.ParkingTime) []LineItem {
 	minutes := parkingTime.CalculateMinutes()
 	if hourInterval.isInRange(minutes) {
-		return hourInterval.price
+		return []LineItem{newLineItem(HourIntervalModel, hourInterval.TimeConstraint, 1, UnitFlat, hourInterval.price)}
 	}
-	return NOTINRANGE
+	return nil
 }
 
-func NewHourInterval(price float64, constraint TimeConstraint) ModelCalculator {
+func NewHourInterval(price money.Money, constraint TimeConstraint) ModelCalculator {
 	return &HourInterval{price: price,
 		TimeConstraint: constraint}
 }
@@ -92,18 +138,22 @@ func NewHourInterval(price float64, constraint TimeConstraint) ModelCalculator {
 // PreviousHourInterval Model :  include cost if park hour is greater than range value
 type PreviousHourInterval struct {
 	TimeConstraint
-	price float64
+	price money.Money
+}
+
+func (previousHourInterval *PreviousHourInterval) GetCost(parkingTime slot.ParkingTime) money.Money {
+	return getCost(previousHourInterval.Itemize(parkingTime), previousHourInterval.price.Currency())
 }
 
-func (previousHourInterval *PreviousHourInterval) GetCost(parkingTime slot.ParkingTime) float64 {
+func (previousHourInterval *PreviousHourInterval) Itemize(parkingTime slot.ParkingTime) []LineItem {
 	minutes := parkingTime.CalculateMinutes()
 	if previousHourInterval.isGreater(minutes) || previousHourInterval.isInRange(minutes) {
-		return previousHourInterval.price
+		return []LineItem{newLineItem(PreviousHourIntervalModel, previousHourInterval.TimeConstraint, 1, UnitFlat, previousHourInterval.price)}
 	}
-	return NOTINRANGE
+	return nil
 }
 
-func NewPreviousHourInterval(price float64, constraint TimeConstraint) ModelCalculator {
+func NewPreviousHourInterval(price money.Money, constraint TimeConstraint) ModelCalculator {
 	return &PreviousHourInterval{price: price,
 		TimeConstraint: constraint}
 }
@@ -111,26 +161,36 @@ func NewPreviousHourInterval(price float64, constraint TimeConstraint) ModelCalc
 // EveryHourInInterval Model :  hourly price for the range values
 type EveryHourInInterval struct {
 	TimeConstraint
-	price float64
+	price       money.Money
+	granularity Granularity
 }
 
-func (everyHourInInterval *EveryHourInInterval) GetCost(parkingTime slot.ParkingTime) float64 {
+func (everyHourInInterval *EveryHourInInterval) GetCost(parkingTime slot.ParkingTime) money.Money {
+	return getCost(everyHourInInterval.Itemize(parkingTime), everyHourInInterval.price.Currency())
+}
+
+func (everyHourInInterval *EveryHourInInterval) Itemize(parkingTime slot.ParkingTime) []LineItem {
 	mins := parkingTime.CalculateMinutes()
 	if everyHourInInterval.isInRange(mins) {
 		minutesOffSet := mins - everyHourInInterval.start
-		return math.Ceil(MintoHr(minutesOffSet)) * everyHourInInterval.price
+		return []LineItem{everyHourInInterval.granularity.item(EveryHourInIntervalModel, everyHourInInterval.TimeConstraint,
+			minutesOffSet, UnitHour, HrtoMinutes(1), everyHourInInterval.price)}
 	}
-	return NOTINRANGE
+	return nil
 }
 
-func NewEveryHourInInterval(price float64, constraint TimeConstraint) *EveryHourInInterval {
+func NewEveryHourInInterval(price money.Money, constraint TimeConstraint) *EveryHourInInterval {
 	return &EveryHourInInterval{price: price,
 		TimeConstraint: constraint}
 }
 
 type Tariff interface {
 	ModelCalculator
+	Itemizer
 	Append(calculator ModelCalculator)
+	GetModels() []ModelCalculator
+	GetSpec() (TariffSpec, error)
+	GetCurrency() string
 }
 
 type BaseTariff struct {
@@ -141,22 +201,49 @@ func (baseTariff *BaseTariff) Append(calculator ModelCalculator) {
 	baseTariff.orderedTarrif = append(baseTariff.orderedTarrif, calculator)
 }
 
+// GetCurrency returns the currency of the tariff prices, taken from the first model,
+// Validate reports models priced in another currency
+func (baseTariff *BaseTariff) GetCurrency() string {
+	for _, model := range baseTariff.orderedTarrif {
+		if currency, ok := getModelCurrency(model); ok {
+			return currency
+		}
+	}
+	return ""
+}
+
+// GetModels returns the models in match order
+func (baseTariff *BaseTariff) GetModels() []ModelCalculator {
+	models := make([]ModelCalculator, len(baseTariff.orderedTarrif))
+	copy(models, baseTariff.orderedTarrif)
+	return models
+}
+
 // SingleTariffMatcher : matches with single model in ordered list
 type SingleTariffMatcher struct {
 	BaseTariff
 }
 
-func (singleTariffMatcher *SingleTariffMatcher) GetCost(parkingTime slot.ParkingTime) float64 {
-	var cost float64
+func (singleTariffMatcher *SingleTariffMatcher) GetCost(parkingTime slot.ParkingTime) money.Money {
+	var cost money.Money
 	for _, v := range singleTariffMatcher.orderedTarrif {
 		cost = v.GetCost(parkingTime)
-		if cost > NOTINRANGE {
+		if IsInRange(cost) {
 			break
 		}
 	}
 	return cost
 }
 
+func (singleTariffMatcher *SingleTariffMatcher) Itemize(parkingTime slot.ParkingTime) []LineItem {
+	for _, v := range singleTariffMatcher.orderedTarrif {
+		if items := Itemize(v, parkingTime); items != nil {
+			return items
+		}
+	}
+	return nil
+}
+
 func NewSingleTariffMatcher() Tariff {
 	return &SingleTariffMatcher{}
 }
@@ -166,18 +253,78 @@ type MultipleTariffMatcher struct {
 	BaseTariff
 }
 
-func (multipleTariffMatcher *MultipleTariffMatcher) GetCost(parkingTime slot.ParkingTime) float64 {
-	var sum float64
+func (multipleTariffMatcher *MultipleTariffMatcher) GetCost(parkingTime slot.ParkingTime) money.Money {
+	var sum money.Money
 	for _, v := range multipleTariffMatcher.orderedTarrif {
 		cost := v.GetCost(parkingTime)
-		if cost != NOTINRANGE {
-			sum += cost
+		if IsInRange(cost) {
+			sum = sum.Add(cost)
 		}
-		//sum += v.GetCost(parkingTime)
 	}
 	return sum
 }
 
+func (multipleTariffMatcher *MultipleTariffMatcher) Itemize(parkingTime slot.ParkingTime) []LineItem {
+	var items []LineItem
+	for _, v := range multipleTariffMatcher.orderedTarrif {
+		items = append(items, Itemize(v, parkingTime)...)
+	}
+	return items
+}
+
 func NewMultipleTariffMatcher() Tariff {
 	return &MultipleTariffMatcher{}
 }
+
+// CheapestTariffMatcher : charges the cheapest of the matching models, the customer gets the best price
+type CheapestTariffMatcher struct {
+	BaseTariff
+}
+
+func (cheapestTariffMatcher *CheapestTariffMatcher) GetCost(parkingTime slot.ParkingTime) money.Money {
+	return getCost(cheapestTariffMatcher.Itemize(parkingTime), cheapestTariffMatcher.GetCurrency())
+}
+
+func (cheapestTariffMatcher *CheapestTariffMatcher) Itemize(parkingTime slot.ParkingTime) []LineItem {
+	return cheapestTariffMatcher.pick(parkingTime, -1)
+}
+
+func NewCheapestTariffMatcher() Tariff {
+	return &CheapestTariffMatcher{}
+}
+
+// MaxTariffMatcher : charges the most expensive of the matching models, used for minimum charges
+type MaxTariffMatcher struct {
+	BaseTariff
+}
+
+func (maxTariffMatcher *MaxTariffMatcher) GetCost(parkingTime slot.ParkingTime) money.Money {
+	return getCost(maxTariffMatcher.Itemize(parkingTime), maxTariffMatcher.GetCurrency())
+}
+
+func (maxTariffMatcher *MaxTariffMatcher) Itemize(parkingTime slot.ParkingTime) []LineItem {
+	return maxTariffMatcher.pick(parkingTime, 1)
+}
+
+func NewMaxTariffMatcher() Tariff {
+	return &MaxTariffMatcher{}
+}
+
+// pick returns the items of the matching model whose cost compares as order to the others,
+// -1 for the cheapest and 1 for the most expensive, the first model wins ties
+func (baseTariff *BaseTariff) pick(parkingTime slot.ParkingTime, order int) []LineItem {
+	var picked []LineItem
+	var pickedCost money.Money
+	for _, v := range baseTariff.orderedTarrif {
+		cost := v.GetCost(parkingTime)
+		if !IsInRange(cost) {
+			continue
+		}
+		if picked == nil || cost.Cmp(pickedCost) == order {
+			if items := Itemize(v, parkingTime); items != nil {
+				picked, pickedCost = items, cost
+			}
+		}
+	}
+	return picked
+}
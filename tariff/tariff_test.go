@@ -1,23 +1,29 @@
 package tariff
 
 import (
+	"errors"
 	"fmt"
+	"github.com/hbkkanna/parking/money"
 	"github.com/hbkkanna/parking/slot"
 	"math"
 	"testing"
 	"time"
 )
 
+func usd(major float64) money.Money {
+	return money.FromMajor(major, "USD", money.RoundHalfUp)
+}
+
 func TestEveryHour(t *testing.T) {
 	// test case  EveryHour Model
 	tariff1 := NewSingleTariffMatcher()
-	tariff1.Append(NewEveryHour(10))
+	tariff1.Append(NewEveryHour(usd(10)))
 	newTicket := slot.NewTicket(1, slot.NewVehicleSlot(slot.Vehicles[slot.SCOOTER], 2))
 
 	cur := time.Now()
 	newTicket.SetInTime(cur)
 	newTicket.SetOutTime(cur.Add(time.Hour*3 + time.Second*10))
-	if tariff1.GetCost(newTicket) != 40 {
+	if tariff1.GetCost(newTicket) != usd(40) {
 		t.Errorf("flat hourly failed ")
 	} else {
 		fmt.Println(tariff1.GetCost(newTicket))
@@ -28,7 +34,7 @@ func TestEveryHour(t *testing.T) {
 	newTicket.SetInTime(cur)
 	newTicket.SetOutTime(time.Now())
 	fmt.Printf("%.2f", newTicket.CalculateMinutes())
-	if tariff1.GetCost(newTicket) != 10 {
+	if tariff1.GetCost(newTicket) != usd(10) {
 		t.Errorf("flat hourly failed ")
 	} else {
 		fmt.Println(tariff1.GetCost(newTicket))
@@ -38,15 +44,15 @@ func TestEveryHour(t *testing.T) {
 func TestHourInterval(t *testing.T) {
 	// test case EveryHourInInterval Model
 	tariff1 := NewSingleTariffMatcher()
-	tariff1.Append(NewHourInterval(10, TimeConstraint{start: HrtoMinutes(0), end: HrtoMinutes(4)}))
-	tariff1.Append(NewHourInterval(20, TimeConstraint{start: HrtoMinutes(4), end: HrtoMinutes(8)}))
-	tariff1.Append(NewHourInterval(30, TimeConstraint{start: HrtoMinutes(8), end: HrtoMinutes(12)}))
+	tariff1.Append(NewHourInterval(usd(10), TimeConstraint{start: HrtoMinutes(0), end: HrtoMinutes(4)}))
+	tariff1.Append(NewHourInterval(usd(20), TimeConstraint{start: HrtoMinutes(4), end: HrtoMinutes(8)}))
+	tariff1.Append(NewHourInterval(usd(30), TimeConstraint{start: HrtoMinutes(8), end: HrtoMinutes(12)}))
 
 	newTicket := slot.NewTicket(1, slot.NewVehicleSlot(slot.Vehicles[slot.SCOOTER], 2))
 	newTicket.SetInTime(time.Now())
 	newTicket.SetOutTime(time.Now().Add(time.Hour * 5))
 
-	if tariff1.GetCost(newTicket) != 20 {
+	if tariff1.GetCost(newTicket) != usd(20) {
 		fmt.Println(tariff1.GetCost(newTicket))
 		t.Errorf("Hour Interval Model failed ")
 	} else {
@@ -58,7 +64,7 @@ func TestHourInterval(t *testing.T) {
 	newTicket.SetInTime(time.Now())
 	newTicket.SetOutTime(time.Now().Add(time.Hour * 11).Add(time.Minute * 59))
 
-	if tariff1.GetCost(newTicket) != 30 {
+	if tariff1.GetCost(newTicket) != usd(30) {
 		fmt.Println(newTicket.CalculateHours())
 		t.Errorf("Hour Interval Model failed ")
 	} else {
@@ -70,7 +76,7 @@ func TestHourInterval(t *testing.T) {
 	val := time.Now()
 	newTicket.SetInTime(val)
 	newTicket.SetOutTime(val.Add(time.Hour * 12))
-	if tariff1.GetCost(newTicket) != -1 {
+	if IsInRange(tariff1.GetCost(newTicket)) {
 		fmt.Printf("%.2f", newTicket.CalculateHours())
 		fmt.Println(tariff1.GetCost(newTicket))
 
@@ -84,17 +90,17 @@ func TestHourInterval(t *testing.T) {
 func TestEveryHourInInterval(t *testing.T) {
 	// test case EveryDay Model
 	tariff1 := NewSingleTariffMatcher()
-	tariff1.Append(NewHourInterval(10, TimeConstraint{start: HrtoMinutes(0), end: HrtoMinutes(8)}))
-	tariff1.Append(NewHourInterval(20, TimeConstraint{start: HrtoMinutes(8), end: HrtoMinutes(24)}))
+	tariff1.Append(NewHourInterval(usd(10), TimeConstraint{start: HrtoMinutes(0), end: HrtoMinutes(8)}))
+	tariff1.Append(NewHourInterval(usd(20), TimeConstraint{start: HrtoMinutes(8), end: HrtoMinutes(24)}))
 
-	tariff1.Append(NewEveryDay(30, TimeConstraint{start: DaytoMinutes(0), end: math.MaxFloat64}))
+	tariff1.Append(NewEveryDay(usd(30), TimeConstraint{start: DaytoMinutes(0), end: math.MaxFloat64}))
 
 	newTicket := slot.NewTicket(1, slot.NewVehicleSlot(slot.Vehicles[slot.SCOOTER], 2))
 	newTicket.SetInTime(time.Now())
 	newTicket.SetOutTime(time.Now().Add(time.Hour * 24 * 5)) // 6 days
 
 	// 6 * 30
-	if tariff1.GetCost(newTicket) != 180 {
+	if tariff1.GetCost(newTicket) != usd(180) {
 		//fmt.Println(math.Ceil(MintoDays(newTicket.CalculateMinutes())))
 		//fmt.Println(newTicket.CalculateHours())
 		t.Errorf("Hourly Interval Model failed ")
@@ -106,9 +112,9 @@ func TestEveryHourInInterval(t *testing.T) {
 func TestHourlyInterval(t *testing.T) {
 	// test case EveryHourInInterval Model
 	tariff1 := NewSingleTariffMatcher()
-	tariff1.Append(NewHourInterval(10, TimeConstraint{start: HrtoMinutes(0), end: HrtoMinutes(8)}))
-	tariff1.Append(NewHourInterval(20, TimeConstraint{start: HrtoMinutes(8), end: HrtoMinutes(12)}))
-	tariff1.Append(NewEveryHourInInterval(30, TimeConstraint{start: HrtoMinutes(12), end: math.MaxFloat64}))
+	tariff1.Append(NewHourInterval(usd(10), TimeConstraint{start: HrtoMinutes(0), end: HrtoMinutes(8)}))
+	tariff1.Append(NewHourInterval(usd(20), TimeConstraint{start: HrtoMinutes(8), end: HrtoMinutes(12)}))
+	tariff1.Append(NewEveryHourInInterval(usd(30), TimeConstraint{start: HrtoMinutes(12), end: math.MaxFloat64}))
 
 	newTicket := slot.NewTicket(1, slot.NewVehicleSlot(slot.Vehicles[slot.SCOOTER], 2))
 	newTicket.SetInTime(time.Now())
@@ -116,7 +122,7 @@ func TestHourlyInterval(t *testing.T) {
 	fmt.Println(tariff1.GetCost(newTicket))
 
 	// (16-12) * 30
-	if tariff1.GetCost(newTicket) != 120 {
+	if tariff1.GetCost(newTicket) != usd(120) {
 		fmt.Println(newTicket.CalculateHours())
 		t.Errorf("Hourly Interval Model failed ")
 	} else {
@@ -127,9 +133,9 @@ func TestHourlyInterval(t *testing.T) {
 func TestParkingLotTariffSum(t *testing.T) {
 	// test parking Lot Sum & inclusive model
 	tariff1 := NewMultipleTariffMatcher()
-	tariff1.Append(NewPreviousHourInterval(10, TimeConstraint{start: HrtoMinutes(0), end: HrtoMinutes(8)}))
-	tariff1.Append(NewPreviousHourInterval(20, TimeConstraint{start: HrtoMinutes(8), end: HrtoMinutes(12)}))
-	tariff1.Append(NewEveryHourInInterval(30, TimeConstraint{start: HrtoMinutes(12), end: math.MaxFloat64}))
+	tariff1.Append(NewPreviousHourInterval(usd(10), TimeConstraint{start: HrtoMinutes(0), end: HrtoMinutes(8)}))
+	tariff1.Append(NewPreviousHourInterval(usd(20), TimeConstraint{start: HrtoMinutes(8), end: HrtoMinutes(12)}))
+	tariff1.Append(NewEveryHourInInterval(usd(30), TimeConstraint{start: HrtoMinutes(12), end: math.MaxFloat64}))
 
 	newTicket := slot.NewTicket(1, slot.NewVehicleSlot(slot.Vehicles[slot.SCOOTER], 2))
 	newTicket.SetInTime(time.Now())
@@ -137,9 +143,128 @@ func TestParkingLotTariffSum(t *testing.T) {
 	fmt.Println(tariff1.GetCost(newTicket))
 
 	// (16-12) * 30 + 10 + 20 = 150
-	if tariff1.GetCost(newTicket) != 150 {
+	if tariff1.GetCost(newTicket) != usd(150) {
 		t.Errorf("Parking lot tariff failed ")
 	}
 	fmt.Println(tariff1.GetCost(newTicket))
 
 }
+
+func TestItemize(t *testing.T) {
+	// stadium scooter : 30 + 60 + 3 hours * 100
+	tariff1 := NewMultipleTariffMatcher()
+	tariff1.Append(NewPreviousHourInterval(usd(30), TimeConstraint{start: HrtoMinutes(0), end: HrtoMinutes(4)}))
+	tariff1.Append(NewPreviousHourInterval(usd(60), TimeConstraint{start: HrtoMinutes(4), end: HrtoMinutes(12)}))
+	tariff1.Append(NewEveryHourInInterval(usd(100), TimeConstraint{start: HrtoMinutes(12), end: math.MaxFloat64}))
+
+	newTicket := slot.NewTicket(1, slot.NewVehicleSlot(slot.Vehicles[slot.SCOOTER], 2))
+	cur := time.Now()
+	newTicket.SetInTime(cur)
+	newTicket.SetOutTime(cur.Add(time.Hour*14 + time.Minute*59))
+
+	items := tariff1.Itemize(newTicket)
+	if len(items) != 3 || items[2].Units != 3 || items[2].Subtotal != usd(300) || items[2].Unit != UnitHour {
+		t.Fatalf("multiple matcher itemize failed %v", items)
+	}
+	if items[0].String() != "PreviousHourInterval [0s, 4h0m0s): 30.00 USD" {
+		t.Errorf("unexpected line %s", items[0])
+	}
+	if items[2].String() != "EveryHourInInterval [12h0m0s, inf): 3 hour x 100.00 USD = 300.00 USD" {
+		t.Errorf("unexpected line %s", items[2])
+	}
+	if getCost(items, "USD") != tariff1.GetCost(newTicket) {
+		t.Errorf("line items do not add up to the cost")
+	}
+
+	// airport : per day model after the first day
+	tariff2 := NewSingleTariffMatcher()
+	tariff2.Append(NewHourInterval(usd(60), TimeConstraint{start: HrtoMinutes(0), end: HrtoMinutes(24)}))
+	tariff2.Append(NewEveryDay(usd(100), TimeConstraint{start: DaytoMinutes(0), end: math.MaxFloat64}))
+	newTicket.SetOutTime(cur.Add(time.Hour * 73))
+	items = tariff2.Itemize(newTicket)
+	if len(items) != 1 || items[0].Model != EveryDayModel || items[0].Units != 4 || items[0].Subtotal != usd(400) {
+		t.Errorf("single matcher itemize failed %v", items)
+	}
+
+	// not in range
+	tariff3 := NewSingleTariffMatcher()
+	tariff3.Append(NewHourInterval(usd(60), TimeConstraint{start: HrtoMinutes(0), end: HrtoMinutes(1)}))
+	if items := tariff3.Itemize(newTicket); items != nil {
+		t.Errorf("expected no line items %v", items)
+	}
+}
+
+func TestCheapestAndMaxMatcher(t *testing.T) {
+	cur := time.Now()
+	newTicket := slot.NewTicket(1, slot.NewVehicleSlot(slot.Vehicles[slot.SUV], 2))
+	newTicket.SetInTime(cur)
+	newTicket.SetOutTime(cur.Add(time.Hour*5 + time.Minute))
+
+	// 6 hours x 4 = 24, day rate 20, flat 30 for up to 4 hours is out of range
+	cheapest := NewCheapestTariffMatcher()
+	cheapest.Append(NewEveryHour(usd(4)))
+	cheapest.Append(NewEveryDay(usd(20), NewTimeConstraint(0, math.MaxFloat64)))
+	cheapest.Append(NewHourInterval(usd(30), NewTimeConstraint(0, HrtoMinutes(4))))
+	items := cheapest.Itemize(newTicket)
+	if len(items) != 1 || items[0].Model != EveryDayModel || cheapest.GetCost(newTicket) != usd(20) {
+		t.Errorf("cheapest matcher failed %v", items)
+	}
+
+	// minimum charge of 25
+	max := NewMaxTariffMatcher()
+	max.Append(NewEveryHour(usd(4)))
+	max.Append(NewHourInterval(usd(25), NewTimeConstraint(0, math.MaxFloat64)))
+	if cost := max.GetCost(newTicket); cost != usd(25) {
+		t.Errorf("max matcher failed %v", cost)
+	}
+	newTicket.SetOutTime(cur.Add(time.Hour * 7))
+	if cost := max.GetCost(newTicket); cost != usd(28) {
+		t.Errorf("max matcher failed %v", cost)
+	}
+
+	// no model in range
+	none := NewCheapestTariffMatcher()
+	none.Append(NewHourInterval(usd(30), NewTimeConstraint(0, HrtoMinutes(4))))
+	if cost := none.GetCost(newTicket); IsInRange(cost) {
+		t.Errorf("expected not in range, got %v", cost)
+	}
+	var notInRange *NotInRangeError
+	if _, err := Price(none, newTicket); !errors.Is(err, ErrNotInRange) || !errors.As(err, &notInRange) || notInRange.Minutes != HrtoMinutes(7) {
+		t.Errorf("expected ErrNotInRange, got %v", err)
+	}
+	if cost, err := Price(max, newTicket); err != nil || cost != usd(28) {
+		t.Errorf("price failed %v %v", cost, err)
+	}
+}
+
+func TestCompositeMatcher(t *testing.T) {
+	// first match chain plus a flat fee
+	chain := NewSingleTariffMatcher()
+	chain.Append(NewHourInterval(usd(10), NewTimeConstraint(0, HrtoMinutes(2))))
+	chain.Append(NewEveryHourInInterval(usd(8), NewTimeConstraint(HrtoMinutes(2), math.MaxFloat64)))
+	composite := NewMultipleTariffMatcher()
+	composite.Append(chain)
+	composite.Append(NewHourInterval(usd(3), NewTimeConstraint(0, math.MaxFloat64)))
+
+	cur := time.Now()
+	newTicket := slot.NewTicket(1, slot.NewVehicleSlot(slot.Vehicles[slot.SUV], 2))
+	newTicket.SetInTime(cur)
+	newTicket.SetOutTime(cur.Add(time.Hour * 3))
+	items := composite.Itemize(newTicket)
+	if len(items) != 2 || items[0].Model != EveryHourInIntervalModel || composite.GetCost(newTicket) != usd(11) {
+		t.Errorf("composite matcher failed %v", items)
+	}
+	if composite.GetCurrency() != "USD" || len(Validate(composite)) != 0 {
+		t.Errorf("composite matcher validation failed %v", Validate(composite))
+	}
+
+	// a gap in the nested chain is a gap of the composite
+	gapped := NewSingleTariffMatcher()
+	gapped.Append(NewHourInterval(usd(10), NewTimeConstraint(0, HrtoMinutes(2))))
+	outer := NewCheapestTariffMatcher()
+	outer.Append(gapped)
+	issues := Validate(outer)
+	if len(issues) != 1 || issues[0].Kind != IssueGap || issues[0].Start != HrtoMinutes(2) {
+		t.Errorf("expected gap from 2h, got %v", issues)
+	}
+}
@@ -0,0 +1,222 @@
+package tariff
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"math"
+	"strings"
+	"time"
+)
+
+var ErrInvalidBand = errors.New("invalid time band")
+
+var (
+	Weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	Weekend  = []time.Weekday{time.Saturday, time.Sunday}
+)
+
+// Band : wall-clock window of the day priced per hour, a window with From after To wraps
+// past midnight and belongs to the day it starts on. Days limits the band to weekdays,
+// every day when empty.
+type Band struct {
+	Name  string
+	From  time.Duration // offset from midnight
+	To    time.Duration
+	Days  []time.Weekday
+	Price money.Money
+}
+
+func NewBand(name string, from time.Duration, to time.Duration, price money.Money, days ...time.Weekday) Band {
+	return Band{Name: name, From: from, To: to, Days: days, Price: price}
+}
+
+// Validate reports windows outside the day and empty windows
+func (band Band) Validate() error {
+	if band.From < 0 || band.From >= 24*time.Hour || band.To <= 0 || band.To > 24*time.Hour || band.From == band.To {
+		return fmt.Errorf("%w %q from %s to %s", ErrInvalidBand, band.Name, formatClock(band.From), formatClock(band.To))
+	}
+	return nil
+}
+
+func (band Band) isOn(weekday time.Weekday) bool {
+	if len(band.Days) == 0 {
+		return true
+	}
+	for _, day := range band.Days {
+		if day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// window returns the band on the day in minutes from the in-time, the wall clock
+// of the day is used so bands keep their hours across DST changes
+func (band Band) window(day time.Time, inTime time.Time) interval {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, int(band.From.Minutes()), 0, 0, day.Location())
+	endDay := day.Day()
+	if band.To <= band.From {
+		endDay++
+	}
+	end := time.Date(day.Year(), day.Month(), endDay, 0, int(band.To.Minutes()), 0, 0, day.Location())
+	return interval{start.Sub(inTime).Minutes(), end.Sub(inTime).Minutes()}
+}
+
+// TimeOfDay Model : hourly price of the time band the stay is in, a stay is split across
+// the bands it crosses and each band bills its time pro rata. The stay is rounded up to
+// whole hours once, the started hour is billed by the band the stay ends in. Bands are matched in order,
+// time claimed by an earlier band is not billed again and time outside every band
+// is billed at the model price.
+type TimeOfDay struct {
+	price money.Money
+	bands []Band
+}
+
+func (timeOfDay *TimeOfDay) GetCost(parkingTime slot.ParkingTime) money.Money {
+	return getCost(timeOfDay.Itemize(parkingTime), timeOfDay.price.Currency())
+}
+
+func (timeOfDay *TimeOfDay) Itemize(parkingTime slot.ParkingTime) []LineItem {
+	inTime, outTime := parkingTime.GetInTime(), parkingTime.GetOutTime()
+	stay := interval{0, outTime.Sub(inTime).Minutes()}
+	if stay.end <= 0 {
+		// leaving at the in-time bills no hours
+		return []LineItem{newBandItem("", 0, timeOfDay.price)}
+	}
+	var parts []bandPart
+	var claimed []interval
+	for _, band := range timeOfDay.bands {
+		var windows []interval
+		// a band wrapping past midnight may start the day before the in-time
+		day := time.Date(inTime.Year(), inTime.Month(), inTime.Day()-1, 0, 0, 0, 0, inTime.Location())
+		for ; day.Before(outTime); day = day.AddDate(0, 0, 1) {
+			if band.isOn(day.Weekday()) {
+				windows = append(windows, intersect(band.window(day, inTime), []interval{stay})...)
+			}
+		}
+		part := bandPart{band: band.Name, price: band.Price}
+		for _, billed := range subtract(windows, claimed) {
+			part.add(billed, stay)
+			claimed = union(claimed, billed)
+		}
+		parts = append(parts, part)
+	}
+	rest := bandPart{price: timeOfDay.price}
+	for _, billed := range subtract([]interval{stay}, claimed) {
+		rest.add(billed, stay)
+	}
+	parts = append(parts, rest)
+
+	// the minutes rounding the stay up to whole hours go to the band it ends in
+	rounding := HrtoMinutes(math.Ceil(MintoHr(stay.end))) - stay.end
+	var items []LineItem
+	for _, part := range parts {
+		if part.last {
+			part.minutes += rounding
+		}
+		if part.minutes > 0 {
+			items = append(items, newBandItem(part.band, part.minutes, part.price))
+		}
+	}
+	return items
+}
+
+// bandPart : minutes of the stay billed by a band, last when the stay ends in the band
+type bandPart struct {
+	band    string
+	price   money.Money
+	minutes float64
+	last    bool
+}
+
+func (part *bandPart) add(billed interval, stay interval) {
+	part.minutes += billed.end - billed.start
+	part.last = part.last || billed.end >= stay.end
+}
+
+func newBandItem(band string, minutes float64, price money.Money) LineItem {
+	seconds := int64(math.Round(minutes * 60))
+	return LineItem{Model: TimeOfDayModel, Band: band, End: math.MaxFloat64,
+		Units: MintoHr(minutes), Unit: UnitHour, Price: price, Subtotal: price.MulRat(seconds, 3600, money.RoundHalfUp)}
+}
+
+// GetBands returns the bands in match order
+func (timeOfDay *TimeOfDay) GetBands() []Band {
+	bands := make([]Band, len(timeOfDay.bands))
+	copy(bands, timeOfDay.bands)
+	return bands
+}
+
+// NewTimeOfDay creates the model from valid bands priced in the currency of the price,
+// the price bills the time outside every band
+func NewTimeOfDay(price money.Money, bands ...Band) (*TimeOfDay, error) {
+	for _, band := range bands {
+		if err := band.Validate(); err != nil {
+			return nil, err
+		}
+		if !band.Price.SameCurrency(price) {
+			return nil, fmt.Errorf("%w: band %q priced in %s, model in %s", money.ErrCurrencyMismatch,
+				band.Name, band.Price.Currency(), price.Currency())
+		}
+	}
+	return &TimeOfDay{price: price, bands: bands}, nil
+}
+
+// BandSpec : stable JSON form of a band, From and To are "15:04" clock times, "24:00" is the end of
+// the day, Days are short weekday names or "Weekdays" and "Weekend"
+type BandSpec struct {
+	Name  string      `json:"name"`
+	From  string      `json:"from"`
+	To    string      `json:"to"`
+	Days  []string    `json:"days,omitempty"`
+	Price money.Money `json:"price"`
+}
+
+var weekdayNames = map[string][]time.Weekday{
+	"sun": {time.Sunday}, "mon": {time.Monday}, "tue": {time.Tuesday}, "wed": {time.Wednesday},
+	"thu": {time.Thursday}, "fri": {time.Friday}, "sat": {time.Saturday},
+	"weekdays": Weekdays, "weekend": Weekend,
+}
+
+func (band Band) GetSpec() BandSpec {
+	spec := BandSpec{Name: band.Name, From: formatClock(band.From), To: formatClock(band.To), Price: band.Price}
+	for _, day := range band.Days {
+		spec.Days = append(spec.Days, day.String()[:3])
+	}
+	return spec
+}
+
+func (bandSpec BandSpec) Build() (Band, error) {
+	from, err := parseClock(bandSpec.From)
+	if err != nil {
+		return Band{}, err
+	}
+	to, err := parseClock(bandSpec.To)
+	if err != nil {
+		return Band{}, err
+	}
+	var days []time.Weekday
+	for _, name := range bandSpec.Days {
+		weekdays, ok := weekdayNames[strings.ToLower(name)]
+		if !ok {
+			return Band{}, fmt.Errorf("%w: unknown day %q", ErrInvalidBand, name)
+		}
+		days = append(days, weekdays...)
+	}
+	band := NewBand(bandSpec.Name, from, to, bandSpec.Price, days...)
+	return band, band.Validate()
+}
+
+func parseClock(value string) (time.Duration, error) {
+	var hours, minutes int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hours, &minutes); err != nil || len(value) != 5 || minutes < 0 || minutes > 59 {
+		return 0, fmt.Errorf("%w: clock time %q is not hh:mm", ErrInvalidBand, value)
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+func formatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+}
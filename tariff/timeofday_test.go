@@ -0,0 +1,116 @@
+package tariff
+
+import (
+	"errors"
+	"github.com/hbkkanna/parking/money"
+	"github.com/hbkkanna/parking/slot"
+	"testing"
+	"time"
+)
+
+// officeTariff : day rate on weekdays, flat weekend rate, night rate otherwise
+func officeTariff(t *testing.T) *TimeOfDay {
+	model, err := NewTimeOfDay(usd(1),
+		NewBand("Weekend", 0, 24*time.Hour, usd(2), Weekend...),
+		NewBand("Day", 8*time.Hour, 18*time.Hour, usd(5), Weekdays...),
+		NewBand("Night", 18*time.Hour, 8*time.Hour, usd(3)))
+	if err != nil {
+		t.Fatalf("time of day failed %v", err)
+	}
+	return model
+}
+
+func stayAt(inTime time.Time, duration time.Duration) slot.ParkingTime {
+	parkingTime := slot.NewParkingTime()
+	parkingTime.SetInTime(inTime)
+	parkingTime.SetOutTime(inTime.Add(duration))
+	return parkingTime
+}
+
+func TestTimeOfDaySplit(t *testing.T) {
+	model := officeTariff(t)
+	// Wednesday 16:00 to 20:30, 2h day and 2h30m night billed as 3h
+	wednesday := time.Date(2021, 1, 6, 16, 0, 0, 0, time.UTC)
+	items := model.Itemize(stayAt(wednesday, 4*time.Hour+30*time.Minute))
+	if len(items) != 2 || items[0].Band != "Day" || items[0].Units != 2 || items[1].Band != "Night" || items[1].Units != 3 {
+		t.Fatalf("unexpected items %v", items)
+	}
+	if model.GetCost(stayAt(wednesday, 4*time.Hour+30*time.Minute)) != usd(19) {
+		t.Errorf("time of day split failed %v", items)
+	}
+	if items[0].Description() != "TimeOfDay Day" {
+		t.Errorf("unexpected description %s", items[0].Description())
+	}
+}
+
+func TestTimeOfDayBandBoundary(t *testing.T) {
+	model := officeTariff(t)
+	// Wednesday 17:30 to 18:30, half an hour of day and of night is one hour
+	wednesday := time.Date(2021, 1, 6, 17, 30, 0, 0, time.UTC)
+	if cost := model.GetCost(stayAt(wednesday, time.Hour)); cost != money.New(400, "USD") {
+		t.Errorf("band boundary cost %v, expected $4.00 %v", cost, model.Itemize(stayAt(wednesday, time.Hour)))
+	}
+	// 17:30 to 18:40 bills the started second hour at the night rate
+	items := model.Itemize(stayAt(wednesday, time.Hour+10*time.Minute))
+	if len(items) != 2 || items[0].Units != 0.5 || items[1].Units != 1.5 || getCost(items, "USD") != money.New(700, "USD") {
+		t.Errorf("unexpected items %v", items)
+	}
+}
+
+func TestTimeOfDayAcrossDays(t *testing.T) {
+	model := officeTariff(t)
+	// Friday 07:00 to Monday 09:00: Friday night 1h, day 10h, night 6h until Saturday,
+	// 48h weekend, Monday night 8h and day 1h
+	friday := time.Date(2021, 1, 8, 7, 0, 0, 0, time.UTC)
+	cost := model.GetCost(stayAt(friday, 74*time.Hour))
+	expected := usd(2*48 + 5*11 + 3*15)
+	if cost != expected {
+		t.Errorf("multi day stay cost %v, expected %v %v", cost, expected, model.Itemize(stayAt(friday, 74*time.Hour)))
+	}
+}
+
+func TestTimeOfDayUncovered(t *testing.T) {
+	model, err := NewTimeOfDay(usd(1), NewBand("Peak", 8*time.Hour, 10*time.Hour, usd(6), Weekdays...))
+	if err != nil {
+		t.Fatalf("time of day failed %v", err)
+	}
+	monday := time.Date(2021, 1, 4, 7, 0, 0, 0, time.UTC)
+	items := model.Itemize(stayAt(monday, 4*time.Hour))
+	if len(items) != 2 || items[1].Band != "" || items[1].Units != 2 || model.GetCost(stayAt(monday, 4*time.Hour)) != usd(14) {
+		t.Errorf("uncovered time must bill at the model price %v", items)
+	}
+	if cost := model.GetCost(stayAt(monday, 0)); cost != usd(0) {
+		t.Errorf("empty stay must be free, got %v", cost)
+	}
+}
+
+func TestTimeOfDayDaylightSaving(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	model, _ := NewTimeOfDay(usd(1), NewBand("Day", 8*time.Hour, 18*time.Hour, usd(5)), NewBand("Night", 18*time.Hour, 8*time.Hour, usd(3)))
+	// Sunday 28 March 2021 the clocks skip 02:00 to 03:00, the day band still starts at 08:00 local,
+	// Saturday 20:00 to Sunday 09:00 is 11h night and 1h day
+	saturday := time.Date(2021, 3, 27, 20, 0, 0, 0, location)
+	items := model.Itemize(stayAt(saturday, 12*time.Hour))
+	if len(items) != 2 || items[0].Band != "Day" || items[0].Units != 1 || items[1].Band != "Night" || items[1].Units != 11 {
+		t.Errorf("unexpected items over DST change %v", items)
+	}
+}
+
+func TestInvalidBand(t *testing.T) {
+	if _, err := NewTimeOfDay(usd(1), NewBand("Empty", 8*time.Hour, 8*time.Hour, usd(1))); !errors.Is(err, ErrInvalidBand) {
+		t.Errorf("expected ErrInvalidBand, got %v", err)
+	}
+	if _, err := NewTimeOfDay(usd(1), NewBand("Late", 25*time.Hour, 2*time.Hour, usd(1))); !errors.Is(err, ErrInvalidBand) {
+		t.Errorf("expected ErrInvalidBand, got %v", err)
+	}
+	if _, err := NewTimeOfDay(usd(1), NewBand("Euro", 0, 24*time.Hour, money.New(100, "EUR"))); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	_, err := UnmarshalModel([]byte(`{"model":"TimeOfDay","price":"1 USD","bands":[{"name":"Day","from":"8am","to":"18:00","price":"5 USD"}]}`))
+	if !errors.Is(err, ErrInvalidBand) {
+		t.Errorf("expected ErrInvalidBand, got %v", err)
+	}
+}
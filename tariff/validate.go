@@ -0,0 +1,200 @@
+package tariff
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	IssueGap           = "gap"
+	IssueOverlap       = "overlap"
+	IssueUnreachable   = "unreachable"
+	IssueInvertedRange = "inverted range"
+	IssueCurrency      = "currency"
+)
+
+// Issue : problem found in a tariff chain. Gaps, unreachable models and inverted ranges are errors,
+// overlaps are warnings as a catch all model after interval models is a common chain.
+type Issue struct {
+	Kind  string
+	Model int // index of the model in the chain, -1 for gaps
+	Start float64
+	End   float64
+	// Currency of the model price for currency issues
+	Currency string
+}
+
+func (issue Issue) IsError() bool {
+	return issue.Kind != IssueOverlap
+}
+
+func (issue Issue) String() string {
+	interval := fmt.Sprintf("[%s, %s)", formatMinutes(issue.Start), formatMinutes(issue.End))
+	switch issue.Kind {
+	case IssueGap:
+		return fmt.Sprintf("gap: no model matches %s", interval)
+	case IssueOverlap:
+		return fmt.Sprintf("overlap: model %d is shadowed by earlier models in %s", issue.Model, interval)
+	case IssueUnreachable:
+		return fmt.Sprintf("unreachable: model %d range %s is matched by earlier models", issue.Model, interval)
+	case IssueCurrency:
+		return fmt.Sprintf("currency: model %d is priced in %s", issue.Model, issue.Currency)
+	}
+	return fmt.Sprintf("inverted range: model %d has range %s", issue.Model, interval)
+}
+
+func formatMinutes(minutes float64) string {
+	if minutes == math.MaxFloat64 {
+		return "inf"
+	}
+	return minutesToDuration(minutes)
+}
+
+// wrapper is implemented by tariffs that price with other tariffs
+type wrapper interface {
+	chains() []Tariff
+}
+
+// interval : half open range of minutes
+type interval struct {
+	start float64
+	end   float64
+}
+
+// Validate checks the tariff chain before it goes live. Stays outside every model cost NOTINRANGE,
+// so every gap in the coverage of [0, inf) is reported, as are models priced in another currency.
+// Models that are not Specifier are assumed to match every stay, nested matchers cover the stays
+// outside their own gaps. Tariffs wrapping other tariffs, such as caps, grace periods and event
+// pricing, are validated by the chains they wrap.
+func Validate(tariff Tariff) []Issue {
+	if wrapper, ok := tariff.(wrapper); ok {
+		var issues []Issue
+		for _, chain := range wrapper.chains() {
+			issues = append(issues, Validate(chain)...)
+		}
+		return issues
+	}
+	var issues []Issue
+	var covered []interval
+	_, firstMatch := tariff.(*SingleTariffMatcher)
+	for i, model := range tariff.GetModels() {
+		if currency, ok := getModelCurrency(model); ok && currency != tariff.GetCurrency() {
+			issues = append(issues, Issue{Kind: IssueCurrency, Model: i, Currency: currency})
+		}
+		if nested, ok := model.(Tariff); ok {
+			// a nested matcher covers every stay outside its own gaps
+			nestedCoverage := []interval{{0, math.MaxFloat64}}
+			for _, issue := range Validate(nested) {
+				switch issue.Kind {
+				case IssueGap:
+					nestedCoverage = subtract(nestedCoverage, []interval{{issue.Start, issue.End}})
+				case IssueCurrency:
+					// reported at the nested matcher, a mixed chain can not price any stay
+					issues = append(issues, Issue{Kind: IssueCurrency, Model: i, Currency: issue.Currency})
+				}
+			}
+			for _, nestedRange := range nestedCoverage {
+				covered = union(covered, nestedRange)
+			}
+			continue
+		}
+		modelRange, ok := getCoverage(model)
+		if !ok {
+			covered = union(covered, interval{0, math.MaxFloat64})
+			continue
+		}
+		if modelRange.start < 0 || modelRange.start >= modelRange.end {
+			issues = append(issues, Issue{Kind: IssueInvertedRange, Model: i, Start: modelRange.start, End: modelRange.end})
+			continue
+		}
+		if firstMatch {
+			if len(subtract([]interval{modelRange}, covered)) == 0 {
+				issues = append(issues, Issue{Kind: IssueUnreachable, Model: i, Start: modelRange.start, End: modelRange.end})
+				continue
+			}
+			for _, shadowed := range intersect(modelRange, covered) {
+				issues = append(issues, Issue{Kind: IssueOverlap, Model: i, Start: shadowed.start, End: shadowed.end})
+			}
+		}
+		covered = union(covered, modelRange)
+	}
+	for _, gap := range subtract([]interval{{0, math.MaxFloat64}}, covered) {
+		issues = append(issues, Issue{Kind: IssueGap, Model: -1, Start: gap.start, End: gap.end})
+	}
+	return issues
+}
+
+// getCoverage returns the stays in minutes the model charges for
+func getCoverage(model ModelCalculator) (interval, bool) {
+	specifier, ok := model.(Specifier)
+	if !ok {
+		return interval{}, false
+	}
+	spec := specifier.GetSpec()
+	constraint, err := spec.GetConstraint()
+	if err != nil {
+		return interval{}, false
+	}
+	switch spec.Model {
+	case EveryHourModel:
+		return interval{0, math.MaxFloat64}, true
+	case PreviousHourIntervalModel:
+		// charged for every stay beyond the range as well, the range bounds are still checked
+		if constraint.start >= 0 && constraint.start < constraint.end {
+			return interval{constraint.start, math.MaxFloat64}, true
+		}
+	}
+	return interval{constraint.start, constraint.end}, true
+}
+
+// union adds the interval to the sorted, disjoint intervals
+func union(intervals []interval, added interval) []interval {
+	all := append(append([]interval{}, intervals...), added)
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].start < all[j].start
+	})
+	merged := []interval{all[0]}
+	for _, next := range all[1:] {
+		last := &merged[len(merged)-1]
+		if next.start <= last.end {
+			last.end = math.Max(last.end, next.end)
+			continue
+		}
+		merged = append(merged, next)
+	}
+	return merged
+}
+
+// subtract removes the sorted, disjoint intervals from each interval
+func subtract(intervals []interval, removed []interval) []interval {
+	var result []interval
+	for _, current := range intervals {
+		start := current.start
+		for _, cut := range removed {
+			if cut.end <= start || cut.start >= current.end {
+				continue
+			}
+			if cut.start > start {
+				result = append(result, interval{start, cut.start})
+			}
+			start = math.Max(start, cut.end)
+		}
+		if start < current.end {
+			result = append(result, interval{start, current.end})
+		}
+	}
+	return result
+}
+
+func intersect(current interval, intervals []interval) []interval {
+	var result []interval
+	for _, other := range intervals {
+		start := math.Max(current.start, other.start)
+		end := math.Min(current.end, other.end)
+		if start < end {
+			result = append(result, interval{start, end})
+		}
+	}
+	return result
+}
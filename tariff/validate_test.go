@@ -0,0 +1,109 @@
+package tariff
+
+import (
+	"github.com/hbkkanna/parking/money"
+	"math"
+	"testing"
+)
+
+func issueKinds(issues []Issue) []string {
+	var kinds []string
+	for _, issue := range issues {
+		kinds = append(kinds, issue.Kind)
+	}
+	return kinds
+}
+
+func TestValidateAirportChain(t *testing.T) {
+	tariff := NewSingleTariffMatcher()
+	tariff.Append(NewHourInterval(usd(0), NewTimeConstraint(HrtoMinutes(0), HrtoMinutes(1))))
+	tariff.Append(NewHourInterval(usd(40), NewTimeConstraint(HrtoMinutes(1), HrtoMinutes(8))))
+	tariff.Append(NewHourInterval(usd(60), NewTimeConstraint(HrtoMinutes(8), HrtoMinutes(24))))
+	tariff.Append(NewEveryDay(usd(80), NewTimeConstraint(DaytoMinutes(0), math.MaxFloat64)))
+
+	// catch all per day model is shadowed for the first day only
+	issues := Validate(tariff)
+	if len(issues) != 1 || issues[0].Kind != IssueOverlap || issues[0].IsError() || issues[0].End != HrtoMinutes(24) {
+		t.Errorf("unexpected issues %v", issues)
+	}
+}
+
+func TestValidateGap(t *testing.T) {
+	tariff := NewSingleTariffMatcher()
+	tariff.Append(NewHourInterval(usd(10), NewTimeConstraint(HrtoMinutes(0), HrtoMinutes(4))))
+	tariff.Append(NewHourInterval(usd(20), NewTimeConstraint(HrtoMinutes(5), HrtoMinutes(8))))
+
+	issues := Validate(tariff)
+	if len(issues) != 2 || issues[0].Kind != IssueGap || issues[0].Start != HrtoMinutes(4) || issues[0].End != HrtoMinutes(5) {
+		t.Fatalf("unexpected issues %v", issues)
+	}
+	if issues[1].Kind != IssueGap || issues[1].Start != HrtoMinutes(8) || issues[1].End != math.MaxFloat64 {
+		t.Errorf("unexpected issues %v", issues)
+	}
+	if issues[1].String() != "gap: no model matches [8h0m0s, inf)" {
+		t.Errorf("unexpected message %s", issues[1])
+	}
+
+	if issues := Validate(NewMultipleTariffMatcher()); len(issues) != 1 || issues[0].Kind != IssueGap {
+		t.Errorf("empty tariff should be one gap, got %v", issues)
+	}
+}
+
+func TestValidateUnreachableAndInverted(t *testing.T) {
+	tariff := NewSingleTariffMatcher()
+	tariff.Append(NewEveryHour(usd(10)))
+	tariff.Append(NewHourInterval(usd(20), NewTimeConstraint(HrtoMinutes(4), HrtoMinutes(8))))
+	tariff.Append(NewHourInterval(usd(20), NewTimeConstraint(HrtoMinutes(8), HrtoMinutes(4))))
+
+	issues := Validate(tariff)
+	kinds := issueKinds(issues)
+	if len(kinds) != 2 || kinds[0] != IssueUnreachable || kinds[1] != IssueInvertedRange || issues[1].Model != 2 {
+		t.Errorf("unexpected issues %v", issues)
+	}
+}
+
+func TestValidateMultipleMatcher(t *testing.T) {
+	// stadium chain : previous intervals overlap by design
+	tariff := NewMultipleTariffMatcher()
+	tariff.Append(NewPreviousHourInterval(usd(30), NewTimeConstraint(HrtoMinutes(0), HrtoMinutes(4))))
+	tariff.Append(NewPreviousHourInterval(usd(60), NewTimeConstraint(HrtoMinutes(4), HrtoMinutes(12))))
+	tariff.Append(NewEveryHourInInterval(usd(100), NewTimeConstraint(HrtoMinutes(12), math.MaxFloat64)))
+	if issues := Validate(tariff); len(issues) != 0 {
+		t.Errorf("unexpected issues %v", issues)
+	}
+
+	tariff = NewMultipleTariffMatcher()
+	tariff.Append(NewHourInterval(usd(30), NewTimeConstraint(HrtoMinutes(1), HrtoMinutes(4))))
+	issues := Validate(tariff)
+	if kinds := issueKinds(issues); len(kinds) != 2 || kinds[0] != IssueGap || kinds[1] != IssueGap {
+		t.Errorf("unexpected issues %v", issues)
+	}
+}
+
+func TestValidateCurrency(t *testing.T) {
+	tariff := NewSingleTariffMatcher()
+	tariff.Append(NewHourInterval(usd(10), NewTimeConstraint(HrtoMinutes(0), HrtoMinutes(4))))
+	tariff.Append(NewEveryHour(money.New(2000, "EUR")))
+	if tariff.GetCurrency() != "USD" {
+		t.Errorf("unexpected currency %s", tariff.GetCurrency())
+	}
+	issues := Validate(tariff)
+	if len(issues) != 2 || issues[0].Kind != IssueCurrency || issues[0].Model != 1 || !issues[0].IsError() {
+		t.Errorf("unexpected issues %v", issues)
+	}
+	if issues[0].String() != "currency: model 1 is priced in EUR" {
+		t.Errorf("unexpected message %s", issues[0])
+	}
+}
+
+func TestValidateNestedCurrency(t *testing.T) {
+	nested := NewMultipleTariffMatcher()
+	nested.Append(NewEveryHour(usd(1)))
+	nested.Append(NewEveryHour(money.New(100, "EUR")))
+	tariff := NewSingleTariffMatcher()
+	tariff.Append(nested)
+	issues := Validate(tariff)
+	if len(issues) != 1 || issues[0].Kind != IssueCurrency || issues[0].Model != 0 || issues[0].Currency != "EUR" {
+		t.Errorf("unexpected issues %v", issues)
+	}
+}
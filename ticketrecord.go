@@ -0,0 +1,193 @@
+package parking
+
+import (
+	"errors"
+	"github.com/hbkkanna/parking/slot"
+	"sort"
+	"sync"
+)
+
+const (
+	TicketIssued = iota
+	TicketRedeemed
+	TicketVoided
+)
+
+var (
+	ErrTicketUnknown     = errors.New("ticket unknown")
+	ErrTicketAlreadyUsed = errors.New("ticket already used")
+	ErrTicketVoided      = errors.New("ticket voided")
+	ErrSlotMismatch      = errors.New("ticket does not match slot occupancy")
+	ErrPlateParked       = errors.New("plate already parked")
+	ErrPlateNotFound     = errors.New("plate not parked")
+	ErrSlotFree          = errors.New("no vehicle parked in slot")
+)
+
+// ticketRecord : lot side copy of an issued ticket, bound to the slot it occupies
+type ticketRecord struct {
+	ticketNumber int
+	vehicleType  int
+	slotNumber   int
+	plate        string
+	// tariffType is the vehicle type whose tariff bills the stay
+	tariffType int
+	status     int
+}
+
+// pendingTicket : ticket number of a reserved plate until its ticket is issued, tickets are numbered from 1
+const pendingTicket = 0
+
+// ticketRegistry keeps every ticket issued by the lot, the current occupant of each slot
+// and the ticket of each parked plate, plates are kept normalized
+type ticketRegistry struct {
+	mutex     sync.Mutex
+	records   map[int]*ticketRecord
+	occupants map[int]map[int]int
+	plates    map[string]int
+}
+
+func newTicketRegistry() *ticketRegistry {
+	return &ticketRegistry{
+		records:   make(map[int]*ticketRecord),
+		occupants: make(map[int]map[int]int),
+		plates:    make(map[string]int),
+	}
+}
+
+// reserve holds the plate for a ticket about to be issued, it is refused while the plate is
+// parked or being parked. Vehicles without a plate are not tracked.
+func (registry *ticketRegistry) reserve(plate string) error {
+	if plate == "" {
+		return nil
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if parked, ok := registry.plates[slot.NormalizePlate(plate)]; ok {
+		return &PlateParkedError{Plate: plate, TicketNumber: parked}
+	}
+	registry.plates[slot.NormalizePlate(plate)] = pendingTicket
+	return nil
+}
+
+// release drops the reservation of a plate whose ticket was not issued
+func (registry *ticketRegistry) release(plate string) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if registry.plates[slot.NormalizePlate(plate)] == pendingTicket {
+		delete(registry.plates, slot.NormalizePlate(plate))
+	}
+}
+
+// find returns the issued ticket the plate is parked under
+func (registry *ticketRegistry) find(plate string) (ticketRecord, bool) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	ticketNumber, ok := registry.plates[slot.NormalizePlate(plate)]
+	if !ok || plate == "" {
+		return ticketRecord{}, false
+	}
+	record, ok := registry.records[ticketNumber]
+	if !ok {
+		return ticketRecord{}, false
+	}
+	return *record, true
+}
+
+// occupant returns the issued ticket holding the slot
+func (registry *ticketRegistry) occupant(vehicleType int, slotNumber int) (ticketRecord, bool) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	ticketNumber, ok := registry.occupants[vehicleType][slotNumber]
+	if !ok {
+		return ticketRecord{}, false
+	}
+	record, ok := registry.records[ticketNumber]
+	if !ok {
+		return ticketRecord{}, false
+	}
+	return *record, true
+}
+
+// tariffType returns the vehicle type whose tariff bills the ticket, the slot type unless the
+// vehicle overflowed into the slot
+func (registry *ticketRegistry) tariffType(ticketNumber int, slotType int) int {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if record, ok := registry.records[ticketNumber]; ok {
+		return record.tariffType
+	}
+	return slotType
+}
+
+func (registry *ticketRegistry) issue(ticketNumber int, vehicleType int, slotNumber int, plate string, tariffType int) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.records[ticketNumber] = &ticketRecord{
+		ticketNumber: ticketNumber,
+		vehicleType:  vehicleType,
+		slotNumber:   slotNumber,
+		plate:        plate,
+		tariffType:   tariffType,
+		status:       TicketIssued,
+	}
+	if plate != "" {
+		registry.plates[slot.NormalizePlate(plate)] = ticketNumber
+	}
+	if registry.occupants[vehicleType] == nil {
+		registry.occupants[vehicleType] = make(map[int]int)
+	}
+	registry.occupants[vehicleType][slotNumber] = ticketNumber
+}
+
+// validate checks the presented ticket against the issued record and the slot occupancy
+func (registry *ticketRegistry) validate(ticketNumber int, vehicleType int, slotNumber int) error {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	record, ok := registry.records[ticketNumber]
+	if !ok {
+		return &TicketError{TicketNumber: ticketNumber, Err: ErrTicketUnknown}
+	}
+	switch record.status {
+	case TicketRedeemed:
+		return &TicketError{TicketNumber: ticketNumber, Err: ErrTicketAlreadyUsed}
+	case TicketVoided:
+		return &TicketError{TicketNumber: ticketNumber, Err: ErrTicketVoided}
+	}
+	if record.vehicleType != vehicleType || record.slotNumber != slotNumber {
+		return &TicketError{TicketNumber: ticketNumber, Err: ErrSlotMismatch}
+	}
+	if occupant, ok := registry.occupants[vehicleType][slotNumber]; !ok || occupant != ticketNumber {
+		return &TicketError{TicketNumber: ticketNumber, Err: ErrSlotMismatch}
+	}
+	return nil
+}
+
+// close marks the ticket redeemed or voided and releases its slot
+func (registry *ticketRegistry) close(ticketNumber int, status int) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	record, ok := registry.records[ticketNumber]
+	if !ok {
+		return
+	}
+	record.status = status
+	if registry.occupants[record.vehicleType][record.slotNumber] == ticketNumber {
+		delete(registry.occupants[record.vehicleType], record.slotNumber)
+	}
+	if record.plate != "" && registry.plates[slot.NormalizePlate(record.plate)] == ticketNumber {
+		delete(registry.plates, slot.NormalizePlate(record.plate))
+	}
+}
+
+func (registry *ticketRegistry) list() []ticketRecord {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	var records []ticketRecord
+	for _, record := range registry.records {
+		records = append(records, *record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].ticketNumber < records[j].ticketNumber
+	})
+	return records
+}
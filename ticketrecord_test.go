@@ -0,0 +1,150 @@
+package parking
+
+import (
+	"errors"
+	"github.com/hbkkanna/parking/slot"
+	"testing"
+	"time"
+)
+
+func TestUnParkRejectsUsedTicket(t *testing.T) {
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot(SmallParkingLotConfig(), WithClock(clock))
+
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	clock.Advance(time.Hour)
+	if _, err := plot.UnPark(ticket); err != nil {
+		t.Fatalf("unpark failed %v", err)
+	}
+	if _, err := plot.UnPark(ticket); !errors.Is(err, ErrTicketAlreadyUsed) {
+		t.Errorf("expected ErrTicketAlreadyUsed, got %v", err)
+	}
+
+	// stale ticket : slot is now held by another car
+	other, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	if other.GetNumber() != ticket.GetNumber() {
+		t.Fatalf("expected slot reuse")
+	}
+	if _, err := plot.UnPark(ticket); !errors.Is(err, ErrTicketAlreadyUsed) {
+		t.Errorf("expected ErrTicketAlreadyUsed, got %v", err)
+	}
+	if other.IsFree() {
+		t.Errorf("stale ticket released the slot of another car")
+	}
+	clock.Advance(time.Hour * 2)
+	receipt, err := plot.UnPark(other)
+	if err != nil || receipt.GetCost() != usd(20) {
+		t.Errorf("unpark of current occupant failed %v", err)
+	}
+}
+
+func TestUnParkRejectsForgedTicket(t *testing.T) {
+	plot := NewParkingLot(SmallParkingLotConfig())
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+
+	// unknown ticket number, slot number out of range
+	forged := slot.NewTicket(999, slot.NewVehicleSlot(slot.Vehicles[slot.SCOOTER], 5000))
+	if _, err := plot.UnPark(forged); !errors.Is(err, ErrTicketUnknown) {
+		t.Errorf("expected ErrTicketUnknown, got %v", err)
+	}
+
+	// known ticket number bound to another slot
+	forged = slot.NewTicket(ticket.GetTicketNumber(), slot.NewVehicleSlot(slot.Vehicles[slot.SCOOTER], 1))
+	if _, err := plot.UnPark(forged); !errors.Is(err, ErrSlotMismatch) {
+		t.Errorf("expected ErrSlotMismatch, got %v", err)
+	}
+
+	// known ticket number with another vehicle type
+	forged = slot.NewTicket(ticket.GetTicketNumber(), slot.NewVehicleSlot(slot.Vehicles[slot.TRUCK], ticket.GetNumber()))
+	if _, err := plot.UnPark(forged); !errors.Is(err, ErrSlotMismatch) {
+		t.Errorf("expected ErrSlotMismatch, got %v", err)
+	}
+
+	if ticket.IsFree() {
+		t.Errorf("forged ticket released the slot")
+	}
+}
+
+func TestVoidTicket(t *testing.T) {
+	plot := NewParkingLot(SmallParkingLotConfig())
+	ticket, _ := plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+	if err := plot.VoidTicket(ticket); err != nil {
+		t.Fatalf("void failed %v", err)
+	}
+	if !ticket.IsFree() {
+		t.Errorf("void did not release the slot")
+	}
+	if _, err := plot.UnPark(ticket); !errors.Is(err, ErrTicketVoided) {
+		t.Errorf("expected ErrTicketVoided, got %v", err)
+	}
+	if err := plot.VoidTicket(ticket); !errors.Is(err, ErrTicketVoided) {
+		t.Errorf("expected ErrTicketVoided, got %v", err)
+	}
+}
+
+func TestGetSlotBounds(t *testing.T) {
+	plot := NewParkingLot(SmallParkingLotConfig())
+	lot, _ := plot.(*VehicleParkingLot)
+	if _, err := lot.getSlot(slot.SCOOTER, 2); err == nil {
+		t.Errorf("expected slot not found")
+	}
+	if _, err := lot.getSlot(slot.SCOOTER, -1); err == nil {
+		t.Errorf("expected slot not found")
+	}
+}
+
+func TestParkByPlate(t *testing.T) {
+	clock := NewFakeClock(time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC))
+	plot := NewParkingLot(MallParkingLotConfig(), WithClock(clock))
+
+	ticket, err := plot.Park(slot.NewRegisteredVehicle(slot.SUV, "AB-12 CD", nil))
+	if err != nil || ticket.GetPlate() != "AB-12 CD" {
+		t.Fatalf("park failed %v", err)
+	}
+	// the same plate can not be parked twice, whatever the vehicle type or spelling
+	_, err = plot.Park(slot.NewRegisteredVehicle(slot.TRUCK, "ab12cd", nil))
+	var plateError *PlateParkedError
+	if !errors.Is(err, ErrPlateParked) || !errors.As(err, &plateError) || plateError.TicketNumber != ticket.GetTicketNumber() {
+		t.Errorf("expected ErrPlateParked, got %v", err)
+	}
+	// vehicles without a plate are not tracked
+	plot.Park(slot.NewRoadVehicle(slot.SUV))
+	if _, err := plot.Park(slot.NewRoadVehicle(slot.SUV)); err != nil {
+		t.Errorf("unregistered vehicles must park %v", err)
+	}
+
+	found, err := plot.FindByPlate("ab 12-cd")
+	if err != nil || found.GetTicketNumber() != ticket.GetTicketNumber() || found.GetNumber() != ticket.GetNumber() {
+		t.Fatalf("find by plate failed %v", err)
+	}
+	clock.Advance(time.Hour)
+	receipt, err := plot.UnPark(found)
+	if err != nil || receipt.GetPlate() != "AB-12 CD" {
+		t.Fatalf("receipt must carry the plate %v %v", receipt, err)
+	}
+	if _, err := plot.FindByPlate("AB12CD"); !errors.Is(err, ErrPlateNotFound) {
+		t.Errorf("expected ErrPlateNotFound, got %v", err)
+	}
+	if _, err := plot.Park(slot.NewRegisteredVehicle(slot.SUV, "AB12CD", nil)); err != nil {
+		t.Errorf("plate must park again after leaving %v", err)
+	}
+}
+
+func TestParkByPlateFullLot(t *testing.T) {
+	plot := NewParkingLot(SmallParkingLotConfig())
+	ticket, _ := plot.Park(slot.NewRegisteredVehicle(slot.SCOOTER, "AB12CD", nil))
+	plot.Park(slot.NewRoadVehicle(slot.SCOOTER))
+
+	// the parked plate is refused before the lot is found full
+	if _, err := plot.Park(slot.NewRegisteredVehicle(slot.SCOOTER, "AB12CD", nil)); !errors.Is(err, ErrPlateParked) {
+		t.Errorf("expected ErrPlateParked, got %v", err)
+	}
+	// a plate turned away for lack of space may park once a slot is free
+	if _, err := plot.Park(slot.NewRegisteredVehicle(slot.SCOOTER, "EF34GH", nil)); !errors.Is(err, ErrNoSpace) {
+		t.Errorf("expected ErrNoSpace, got %v", err)
+	}
+	plot.UnPark(ticket)
+	if _, err := plot.Park(slot.NewRegisteredVehicle(slot.SCOOTER, "EF34GH", nil)); err != nil {
+		t.Errorf("plate must park once a slot is free %v", err)
+	}
+}